@@ -0,0 +1,282 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// AIFF-C compression types for uncompressed audio. Both cases of the float
+// types are in use.
+var aiffCompressions = map[string]struct {
+	float bool
+	order binary.ByteOrder
+}{
+	"NONE": {false, binary.BigEndian},
+	"twos": {false, binary.BigEndian},
+	"sowt": {false, binary.LittleEndian},
+	"fl32": {true, binary.BigEndian},
+	"FL32": {true, binary.BigEndian},
+	"fl64": {true, binary.BigEndian},
+	"FL64": {true, binary.BigEndian},
+}
+
+// DecodeAIFF reads an AIFF or uncompressed AIFF-C file holding integer PCM
+// samples of 1 to 32 bits, or 32 or 64-bit float samples in AIFF-C, with
+// any number of channels.
+func DecodeAIFF(r io.Reader) (*Buffer, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 12 || string(data[0:4]) != "FORM" {
+		return nil, errors.New("aiff: not an IFF file")
+	}
+	form := string(data[8:12])
+	if form != "AIFF" && form != "AIFC" {
+		return nil, errors.New("aiff: IFF file is not AIFF or AIFF-C")
+	}
+
+	var (
+		comm     aiffCommon
+		haveComm bool
+		samples  []byte
+		haveData bool
+	)
+
+	chunks := data[12:]
+	for len(chunks) >= 8 {
+		id := string(chunks[0:4])
+		size := int(binary.BigEndian.Uint32(chunks[4:8]))
+		body := chunks[8:]
+
+		if size > len(body) {
+			size = len(body)
+		}
+		body = body[:size]
+
+		switch id {
+		case "COMM":
+			comm, err = parseAIFFCommon(body, form == "AIFC")
+			if err != nil {
+				return nil, err
+			}
+			haveComm = true
+		case "SSND":
+			if len(body) < 8 {
+				return nil, errors.New("aiff: SSND chunk too short")
+			}
+			offset := int(binary.BigEndian.Uint32(body[0:4]))
+			if 8+offset > len(body) {
+				return nil, errors.New("aiff: SSND offset past the end of the chunk")
+			}
+			samples = body[8+offset:]
+			haveData = true
+		}
+
+		// chunks are padded to an even length
+		next := 8 + size + size%2
+		if next > len(chunks) {
+			break
+		}
+		chunks = chunks[next:]
+	}
+
+	if !haveComm {
+		return nil, errors.New("aiff: missing COMM chunk")
+	}
+	if !haveData {
+		// a file with no frames may leave out the sound data chunk
+		if comm.frames > 0 {
+			return nil, errors.New("aiff: missing SSND chunk")
+		}
+		samples = nil
+	}
+
+	// samples narrower than their container are left-justified, so they
+	// decode as the full container width
+	container := comm.encoding
+	if !container.Float {
+		container.BitDepth = (container.BitDepth + 7) / 8 * 8
+	}
+
+	frameSize := comm.channels * container.BitDepth / 8
+	samples = samples[:min(len(samples)-len(samples)%frameSize, comm.frames*frameSize)]
+
+	decoded, err := decodeSamples(samples, container, comm.order, false)
+	if err != nil {
+		return nil, fmt.Errorf("aiff: %w", err)
+	}
+
+	return &Buffer{
+		Samples:    decoded,
+		SampleRate: comm.rate,
+		Channels:   comm.channels,
+		BitDepth:   comm.encoding.BitDepth,
+	}, nil
+}
+
+// aiffCommon holds the contents of a COMM chunk.
+type aiffCommon struct {
+	channels int
+	frames   int
+	rate     int
+	encoding Encoding
+	order    binary.ByteOrder
+}
+
+func parseAIFFCommon(body []byte, compressed bool) (aiffCommon, error) {
+	if len(body) < 18 || compressed && len(body) < 22 {
+		return aiffCommon{}, errors.New("aiff: COMM chunk too short")
+	}
+
+	comm := aiffCommon{
+		channels: int(binary.BigEndian.Uint16(body[0:2])),
+		frames:   int(binary.BigEndian.Uint32(body[2:6])),
+		encoding: Encoding{BitDepth: int(binary.BigEndian.Uint16(body[6:8]))},
+		order:    binary.BigEndian,
+	}
+
+	rate := readExtended(body[8:18])
+	if !(rate >= 1 && rate < math.MaxInt32) {
+		return aiffCommon{}, fmt.Errorf("aiff: invalid sample rate %g", rate)
+	}
+	comm.rate = int(math.Round(rate))
+
+	if comm.channels == 0 {
+		return aiffCommon{}, errors.New("aiff: file has no channels")
+	}
+
+	if compressed {
+		compression := string(body[18:22])
+		c, ok := aiffCompressions[compression]
+		if !ok {
+			return aiffCommon{}, fmt.Errorf("aiff: unsupported compression %q", compression)
+		}
+		comm.order = c.order
+		if c.float {
+			comm.encoding.Float = true
+			comm.encoding.BitDepth = 32
+			if compression == "fl64" || compression == "FL64" {
+				comm.encoding.BitDepth = 64
+			}
+		}
+	}
+
+	if !comm.encoding.Float && (comm.encoding.BitDepth < 1 || comm.encoding.BitDepth > 32) {
+		return aiffCommon{}, fmt.Errorf("aiff: unsupported sample size %d", comm.encoding.BitDepth)
+	}
+
+	return comm, nil
+}
+
+// EncodeAIFF writes b as an AIFF file with big-endian integer samples, or
+// as an AIFF-C file when enc is a float encoding, which plain AIFF cannot
+// hold.
+func EncodeAIFF(w io.Writer, b *Buffer, enc Encoding) error {
+	if b.Channels <= 0 {
+		return errors.New("aiff: buffer has no channels")
+	}
+	if b.SampleRate <= 0 {
+		return errors.New("aiff: sample rate must be positive")
+	}
+
+	samples, err := encodeSamples(b.Samples, enc, binary.BigEndian, false)
+	if err != nil {
+		return fmt.Errorf("aiff: %w", err)
+	}
+
+	be := binary.BigEndian
+	form := "AIFF"
+
+	var comm bytes.Buffer
+	binary.Write(&comm, be, uint16(b.Channels))
+	binary.Write(&comm, be, uint32(b.Frames()))
+	binary.Write(&comm, be, uint16(enc.BitDepth))
+	comm.Write(writeExtended(float64(b.SampleRate)))
+	if enc.Float {
+		form = "AIFC"
+		name := "IEEE 32-bit float"
+		if enc.BitDepth == 64 {
+			comm.WriteString("fl64")
+			name = "IEEE 64-bit float"
+		} else {
+			comm.WriteString("fl32")
+		}
+		// a Pascal string padded to an even length
+		comm.WriteByte(byte(len(name)))
+		comm.WriteString(name)
+		if len(name)%2 == 0 {
+			comm.WriteByte(0)
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("FORM")
+	size := 4 + 8 + comm.Len() + 8 + 8 + len(samples) + len(samples)%2
+	if enc.Float {
+		size += 8 + 4 // FVER chunk
+	}
+	binary.Write(&out, be, uint32(size))
+	out.WriteString(form)
+
+	if enc.Float {
+		// the version of the AIFF-C specification, which it requires
+		out.WriteString("FVER")
+		binary.Write(&out, be, uint32(4))
+		binary.Write(&out, be, uint32(0xA2805140))
+	}
+
+	out.WriteString("COMM")
+	binary.Write(&out, be, uint32(comm.Len()))
+	out.Write(comm.Bytes())
+
+	out.WriteString("SSND")
+	binary.Write(&out, be, uint32(8+len(samples)))
+	binary.Write(&out, be, uint32(0)) // offset
+	binary.Write(&out, be, uint32(0)) // block size
+	out.Write(samples)
+	if len(samples)%2 == 1 {
+		out.WriteByte(0)
+	}
+
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+// readExtended decodes an 80-bit IEEE 754 extended precision number, which
+// AIFF uses for the sample rate: a sign bit, a 15-bit exponent biased by
+// 16383 and a 64-bit mantissa with an explicit integer bit.
+func readExtended(b []byte) float64 {
+	exponent := int(binary.BigEndian.Uint16(b[0:2]))
+	mantissa := binary.BigEndian.Uint64(b[2:10])
+
+	sign := 1.0
+	if exponent&0x8000 != 0 {
+		sign = -1
+	}
+	exponent &= 0x7FFF
+
+	if exponent == 0x7FFF {
+		return math.NaN()
+	}
+	return sign * math.Ldexp(float64(mantissa), exponent-16383-63)
+}
+
+// writeExtended encodes a non-negative number as an 80-bit extended
+// precision number.
+func writeExtended(v float64) []byte {
+	b := make([]byte, 10)
+	if v <= 0 {
+		return b
+	}
+
+	frac, exponent := math.Frexp(v) // v = frac * 2^exponent, 0.5 <= frac < 1
+	binary.BigEndian.PutUint16(b[0:2], uint16(exponent-1+16383))
+	binary.BigEndian.PutUint64(b[2:10], uint64(math.Ldexp(frac, 64)))
+	return b
+}
@@ -0,0 +1,233 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//go:generate go run testdata/generate.go
+
+// fixtureRamp is the value of frame n of channel c in the files written by
+// testdata/generate.go.
+func fixtureRamp(n, c int) float32 {
+	v := float32(n) / 16
+	if c == 1 {
+		return -v
+	}
+	return v
+}
+
+func testFixture(t *testing.T, name string, b *Buffer, rate, channels, bitDepth int) {
+	t.Helper()
+
+	if b.SampleRate != rate || b.Channels != channels || b.BitDepth != bitDepth {
+		t.Errorf("%s: wrong format. want=%dHz %dch %d-bit, got=%dHz %dch %d-bit", name,
+			rate, channels, bitDepth, b.SampleRate, b.Channels, b.BitDepth)
+	}
+	if b.Frames() != 16 {
+		t.Fatalf("%s: wrong number of frames. want=16, got=%d", name, b.Frames())
+	}
+
+	for i, s := range b.Samples {
+		if want := fixtureRamp(i/channels, i%channels); s != want {
+			t.Errorf("%s: wrong sample %d. want=%g, got=%g", name, i, want, s)
+		}
+	}
+}
+
+func TestDecodeAIFFFixtures(t *testing.T) {
+	tests := []struct {
+		file     string
+		rate     int
+		channels int
+		bitDepth int
+	}{
+		{"stereo16.aiff", 44100, 2, 16},
+		{"mono12.aiff", 8000, 1, 12},
+		{"mono24.aifc", 8000, 1, 24},
+		{"stereo32f.aifc", 44100, 2, 32},
+	}
+
+	for _, tt := range tests {
+		b, err := ReadFile(filepath.Join("testdata", tt.file))
+		if err != nil {
+			t.Fatalf("%s: %s", tt.file, err)
+		}
+
+		testFixture(t, tt.file, b, tt.rate, tt.channels, tt.bitDepth)
+	}
+}
+
+func TestAIFFRoundTrip(t *testing.T) {
+	samples := []float32{0, 0.5, -0.5, 0.25, -1, 0.75, 0.125, -0.25, 0.5, 0}
+
+	tests := []struct {
+		encoding Encoding
+		channels int
+		rate     int
+	}{
+		{Encoding{BitDepth: 8}, 1, 8000},
+		{Encoding{BitDepth: 16}, 2, 44100},
+		{Encoding{BitDepth: 24}, 2, 48000},
+		{Encoding{BitDepth: 32}, 1, 96000},
+		{Encoding{BitDepth: 32, Float: true}, 5, 22050},
+		{Encoding{BitDepth: 64, Float: true}, 1, 11025},
+	}
+
+	for _, tt := range tests {
+		in := &Buffer{Samples: samples, SampleRate: tt.rate, Channels: tt.channels, BitDepth: tt.encoding.BitDepth}
+
+		var file bytes.Buffer
+		if err := EncodeAIFF(&file, in, tt.encoding); err != nil {
+			t.Fatalf("%s: EncodeAIFF failed: %s", tt.encoding, err)
+		}
+
+		form := string(file.Bytes()[8:12])
+		if want := map[bool]string{false: "AIFF", true: "AIFC"}[tt.encoding.Float]; form != want {
+			t.Errorf("%s: wrong form type. want=%s, got=%s", tt.encoding, want, form)
+		}
+
+		out, err := Decode(&file)
+		if err != nil {
+			t.Fatalf("%s: Decode failed: %s", tt.encoding, err)
+		}
+
+		if out.SampleRate != tt.rate || out.Channels != tt.channels || out.BitDepth != tt.encoding.BitDepth {
+			t.Errorf("%s: wrong format. got=%dHz %dch %d-bit",
+				tt.encoding, out.SampleRate, out.Channels, out.BitDepth)
+		}
+
+		frames := len(samples) / tt.channels
+		if len(out.Samples) != frames*tt.channels {
+			t.Fatalf("%s: wrong number of samples. want=%d, got=%d", tt.encoding, frames*tt.channels, len(out.Samples))
+		}
+		for i, s := range out.Samples {
+			if s != samples[i] {
+				t.Errorf("%s: wrong sample %d. want=%g, got=%g", tt.encoding, i, samples[i], s)
+			}
+		}
+	}
+}
+
+func TestWriteFileChoosesContainer(t *testing.T) {
+	dir := t.TempDir()
+	b := &Buffer{Samples: []float32{0.5, -0.5}, SampleRate: 8000, Channels: 1, BitDepth: 16}
+
+	tests := []struct {
+		name  string
+		magic string
+	}{
+		{"out.wav", "RIFF"},
+		{"out.aiff", "FORM"},
+		{"out.AIF", "FORM"},
+		{"out.aifc", "FORM"},
+		{"out", "RIFF"},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := WriteFile(path, b, Encoding{BitDepth: 16}); err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data[0:4]) != tt.magic {
+			t.Errorf("%s: wrong container. want=%s, got=%q", tt.name, tt.magic, data[0:4])
+		}
+
+		out, err := ReadFile(path)
+		if err != nil || len(out.Samples) != 2 || out.Samples[0] != 0.5 {
+			t.Errorf("%s: did not read back. got=%v, %v", tt.name, out, err)
+		}
+	}
+}
+
+func TestExtended(t *testing.T) {
+	tests := []struct {
+		value float64
+		bytes []byte
+	}{
+		{8000, []byte{0x40, 0x0B, 0xFA, 0, 0, 0, 0, 0, 0, 0}},
+		{44100, []byte{0x40, 0x0E, 0xAC, 0x44, 0, 0, 0, 0, 0, 0}},
+		{1, []byte{0x3F, 0xFF, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+		{22050.5, []byte{0x40, 0x0D, 0xAC, 0x45, 0, 0, 0, 0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		if got := writeExtended(tt.value); !bytes.Equal(got, tt.bytes) {
+			t.Errorf("writeExtended(%g) wrong. want=% x, got=% x", tt.value, tt.bytes, got)
+		}
+		if got := readExtended(tt.bytes); got != tt.value {
+			t.Errorf("readExtended(% x) wrong. want=%g, got=%g", tt.bytes, tt.value, got)
+		}
+	}
+
+	if !math.IsNaN(readExtended([]byte{0x7F, 0xFF, 0, 0, 0, 0, 0, 0, 0, 0})) {
+		t.Errorf("an all-ones exponent should be NaN")
+	}
+}
+
+func TestDecodeAIFFErrors(t *testing.T) {
+	be := binary.BigEndian
+	commBody := func(channels uint16, bits uint16, compression string) []byte {
+		out := be.AppendUint16(nil, channels)
+		out = be.AppendUint32(out, 1)
+		out = be.AppendUint16(out, bits)
+		out = append(out, writeExtended(8000)...)
+		if compression != "" {
+			out = append(out, compression...)
+			out = append(out, 0, 0)
+		}
+		return out
+	}
+	aiffChunk := func(id string, body []byte) []byte {
+		return append(be.AppendUint32([]byte(id), uint32(len(body))), body...)
+	}
+	aiffFile := func(form string, chunks ...[]byte) []byte {
+		body := []byte(form)
+		for _, c := range chunks {
+			body = append(body, c...)
+		}
+		return append(be.AppendUint32([]byte("FORM"), uint32(len(body))), body...)
+	}
+
+	tests := []struct {
+		file     []byte
+		expected string
+	}{
+		{[]byte("RIFF"), "not an IFF file"},
+		{aiffFile("8SVX"), "IFF file is not AIFF or AIFF-C"},
+		{aiffFile("AIFF", aiffChunk("SSND", make([]byte, 10))), "missing COMM chunk"},
+		{aiffFile("AIFF", aiffChunk("COMM", commBody(1, 16, ""))), "missing SSND chunk"},
+		{aiffFile("AIFF", aiffChunk("COMM", []byte{0, 1})), "COMM chunk too short"},
+		{aiffFile("AIFC", aiffChunk("COMM", commBody(1, 16, "ima4"))), `unsupported compression "ima4"`},
+		{aiffFile("AIFF", aiffChunk("COMM", commBody(0, 16, ""))), "file has no channels"},
+		{aiffFile("AIFF", aiffChunk("COMM", commBody(1, 40, ""))), "unsupported sample size 40"},
+		{aiffFile("AIFF", aiffChunk("COMM", commBody(1, 16, "")), aiffChunk("SSND", be.AppendUint32(nil, 9))), "SSND chunk too short"},
+	}
+
+	for _, tt := range tests {
+		_, err := DecodeAIFF(bytes.NewReader(tt.file))
+		if err == nil {
+			t.Errorf("expected error %q, got none", tt.expected)
+			continue
+		}
+
+		if !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("wrong error. want=%q, got=%q", tt.expected, err)
+		}
+	}
+
+	if _, err := Decode(strings.NewReader("OggS and some more bytes")); err == nil ||
+		err.Error() != "unrecognized audio format" {
+		t.Errorf("wrong error for an unknown format. got=%v", err)
+	}
+}
@@ -0,0 +1,147 @@
+// Package audio reads and writes PCM audio files. It has no dependencies on
+// the rest of wavy so that it can be used on its own.
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxSamples is the largest number of samples a buffer may hold, so that
+// its length fits in an int on every platform.
+const MaxSamples = math.MaxInt32
+
+// Buffer holds interleaved samples normalized to [-1, 1]. BitDepth records
+// the resolution the samples were decoded from.
+type Buffer struct {
+	Samples    []float32
+	SampleRate int
+	Channels   int
+	BitDepth   int
+}
+
+// Frames returns the number of samples per channel.
+func (b *Buffer) Frames() int {
+	if b.Channels == 0 {
+		return 0
+	}
+	return len(b.Samples) / b.Channels
+}
+
+// Encoding describes how samples are stored in a file.
+type Encoding struct {
+	BitDepth int
+	Float    bool
+}
+
+func (e Encoding) String() string {
+	if e.Float {
+		return fmt.Sprintf("float%d", e.BitDepth)
+	}
+	return fmt.Sprintf("pcm%d", e.BitDepth)
+}
+
+// ParseEncoding parses the names returned by Encoding.String.
+func ParseEncoding(name string) (Encoding, error) {
+	switch name {
+	case "pcm8":
+		return Encoding{BitDepth: 8}, nil
+	case "pcm16":
+		return Encoding{BitDepth: 16}, nil
+	case "pcm24":
+		return Encoding{BitDepth: 24}, nil
+	case "pcm32":
+		return Encoding{BitDepth: 32}, nil
+	case "float32":
+		return Encoding{BitDepth: 32, Float: true}, nil
+	case "float64":
+		return Encoding{BitDepth: 64, Float: true}, nil
+	default:
+		return Encoding{}, fmt.Errorf("unknown encoding %q", name)
+	}
+}
+
+// DefaultEncoding returns the encoding used to write audio of the given bit
+// depth when none is requested: integer PCM up to 24 bits, float above,
+// and 16-bit PCM when the depth is unknown.
+func DefaultEncoding(bitDepth int) Encoding {
+	switch {
+	case bitDepth <= 0:
+		return Encoding{BitDepth: 16}
+	case bitDepth <= 8:
+		return Encoding{BitDepth: 8}
+	case bitDepth <= 16:
+		return Encoding{BitDepth: 16}
+	case bitDepth <= 24:
+		return Encoding{BitDepth: 24}
+	case bitDepth > 32:
+		return Encoding{BitDepth: 64, Float: true}
+	default:
+		return Encoding{BitDepth: 32, Float: true}
+	}
+}
+
+// Decode reads a WAV, AIFF or AIFF-C file, telling them apart by the magic
+// bytes at the start rather than by any file name.
+func Decode(r io.Reader) (*Buffer, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE":
+		return DecodeWAV(bytes.NewReader(data))
+	case len(data) >= 12 && string(data[0:4]) == "FORM":
+		return DecodeAIFF(bytes.NewReader(data))
+	default:
+		return nil, errors.New("unrecognized audio format")
+	}
+}
+
+// ReadFile decodes the audio file at path with Decode.
+func ReadFile(path string) (*Buffer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Decode(f)
+}
+
+// WriteFile encodes b to the file at path, replacing it if it exists. Paths
+// ending in .aif, .aiff or .aifc are written with EncodeAIFF and all others
+// with EncodeWAV.
+func WriteFile(path string, b *Buffer, enc Encoding) error {
+	encode := EncodeWAV
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".aif", ".aiff", ".aifc":
+		encode = EncodeAIFF
+	}
+
+	return writeFile(path, func(w io.Writer) error {
+		return encode(w, b, enc)
+	})
+}
+
+// writeFile creates the file at path and writes it with write.
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
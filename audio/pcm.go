@@ -0,0 +1,139 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// bytesPerSample returns the storage size of one sample, or an error if the
+// encoding is not one this package can handle.
+func bytesPerSample(enc Encoding) (int, error) {
+	switch {
+	case enc.Float && enc.BitDepth == 32:
+		return 4, nil
+	case enc.Float && enc.BitDepth == 64:
+		return 8, nil
+	case !enc.Float && (enc.BitDepth == 8 || enc.BitDepth == 16 ||
+		enc.BitDepth == 24 || enc.BitDepth == 32):
+		return enc.BitDepth / 8, nil
+	default:
+		return 0, fmt.Errorf("unsupported sample encoding: %s", enc)
+	}
+}
+
+// decodeSamples converts packed samples to floats in [-1, 1]. 8-bit samples
+// are unsigned when unsigned8 is set, as in WAV, and signed otherwise.
+func decodeSamples(
+	data []byte,
+	enc Encoding,
+	order binary.ByteOrder,
+	unsigned8 bool,
+) ([]float32, error) {
+	size, err := bytesPerSample(enc)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]float32, len(data)/size)
+
+	for i := range samples {
+		b := data[i*size : (i+1)*size]
+
+		switch {
+		case enc.Float && size == 4:
+			samples[i] = math.Float32frombits(order.Uint32(b))
+		case enc.Float && size == 8:
+			samples[i] = float32(math.Float64frombits(order.Uint64(b)))
+		case size == 1 && unsigned8:
+			samples[i] = float32(int(b[0])-128) / 128
+		case size == 1:
+			samples[i] = float32(int8(b[0])) / 128
+		case size == 2:
+			samples[i] = float32(int16(order.Uint16(b))) / 32768
+		case size == 3:
+			samples[i] = float32(readInt24(b, order)) / 8388608
+		case size == 4:
+			samples[i] = float32(float64(int32(order.Uint32(b))) / 2147483648)
+		}
+	}
+
+	return samples, nil
+}
+
+// encodeSamples packs samples, clipping them to [-1, 1] for integer
+// encodings.
+func encodeSamples(
+	samples []float32,
+	enc Encoding,
+	order binary.ByteOrder,
+	unsigned8 bool,
+) ([]byte, error) {
+	size, err := bytesPerSample(enc)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, len(samples)*size)
+
+	for i, s := range samples {
+		b := data[i*size : (i+1)*size]
+
+		switch {
+		case enc.Float && size == 4:
+			order.PutUint32(b, math.Float32bits(s))
+		case enc.Float && size == 8:
+			order.PutUint64(b, math.Float64bits(float64(s)))
+		case size == 1 && unsigned8:
+			b[0] = byte(quantize(s, 8) + 128)
+		case size == 1:
+			b[0] = byte(int8(quantize(s, 8)))
+		case size == 2:
+			order.PutUint16(b, uint16(int16(quantize(s, 16))))
+		case size == 3:
+			writeInt24(b, int32(quantize(s, 24)), order)
+		case size == 4:
+			order.PutUint32(b, uint32(int32(quantize(s, 32))))
+		}
+	}
+
+	return data, nil
+}
+
+// quantize scales s to a signed integer of the given width, so that values
+// decoded by decodeSamples round-trip exactly.
+func quantize(s float32, bits int) int64 {
+	max := float64(int64(1) << (bits - 1))
+	v := math.Round(float64(s) * max)
+
+	switch {
+	case math.IsNaN(v):
+		return 0
+	case v > max-1:
+		return int64(max - 1)
+	case v < -max:
+		return int64(-max)
+	default:
+		return int64(v)
+	}
+}
+
+func readInt24(b []byte, order binary.ByteOrder) int32 {
+	var v uint32
+	if order == binary.BigEndian {
+		v = uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+	} else {
+		v = uint32(b[2])<<16 | uint32(b[1])<<8 | uint32(b[0])
+	}
+
+	// sign-extend from 24 bits
+	return int32(v<<8) >> 8
+}
+
+func writeInt24(b []byte, v int32, order binary.ByteOrder) {
+	if order == binary.BigEndian {
+		b[0], b[1], b[2] = byte(v>>16), byte(v>>8), byte(v)
+	} else {
+		b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+	}
+}
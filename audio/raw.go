@@ -0,0 +1,136 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// RawFormat describes headerless PCM: the sample encoding and, since no
+// header records it, the byte order.
+type RawFormat struct {
+	Encoding
+	Order    binary.ByteOrder
+	Unsigned bool // 8-bit samples are offset by 128 rather than signed
+}
+
+// String returns the name ParseRawFormat accepts for f.
+func (f RawFormat) String() string {
+	if f.BitDepth == 8 && !f.Float {
+		if f.Unsigned {
+			return "pcm8u"
+		}
+		return "pcm8"
+	}
+	if f.Order == binary.BigEndian {
+		return f.Encoding.String() + "be"
+	}
+	return f.Encoding.String() + "le"
+}
+
+// ParseRawFormat parses an encoding name as accepted by ParseEncoding
+// followed by "le" for little-endian or "be" for big-endian, such as
+// "pcm16le" or "float32be". 8-bit samples have no byte order and are
+// "pcm8" when signed and "pcm8u" when unsigned.
+func ParseRawFormat(name string) (RawFormat, error) {
+	switch name {
+	case "pcm8":
+		return RawFormat{Encoding: Encoding{BitDepth: 8}, Order: binary.LittleEndian}, nil
+	case "pcm8u":
+		return RawFormat{Encoding: Encoding{BitDepth: 8}, Order: binary.LittleEndian, Unsigned: true}, nil
+	}
+
+	var order binary.ByteOrder
+	switch {
+	case strings.HasSuffix(name, "le"):
+		order = binary.LittleEndian
+	case strings.HasSuffix(name, "be"):
+		order = binary.BigEndian
+	default:
+		return RawFormat{}, fmt.Errorf("raw format %q must end in %q or %q", name, "le", "be")
+	}
+
+	enc, err := ParseEncoding(name[:len(name)-2])
+	if err != nil || enc.BitDepth == 8 {
+		return RawFormat{}, fmt.Errorf("unknown raw format %q", name)
+	}
+
+	return RawFormat{Encoding: enc, Order: order}, nil
+}
+
+// DecodeRaw reads headerless samples in the given format. A partial frame
+// at the end is dropped.
+func DecodeRaw(r io.Reader, rate, channels int, format RawFormat) (*Buffer, error) {
+	if rate <= 0 {
+		return nil, fmt.Errorf("raw: sample rate must be positive, got %d", rate)
+	}
+	if channels <= 0 {
+		return nil, fmt.Errorf("raw: channel count must be positive, got %d", channels)
+	}
+	// WAV stores the channel count in 16 bits, and this keeps size*channels
+	// below from overflowing
+	if channels > math.MaxUint16 {
+		return nil, fmt.Errorf("raw: channel count must be at most %d, got %d", math.MaxUint16, channels)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := bytesPerSample(format.Encoding)
+	if err != nil {
+		return nil, fmt.Errorf("raw: %w", err)
+	}
+	data = data[:len(data)-len(data)%(size*channels)]
+
+	samples, err := decodeSamples(data, format.Encoding, format.Order, format.Unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("raw: %w", err)
+	}
+
+	return &Buffer{
+		Samples:    samples,
+		SampleRate: rate,
+		Channels:   channels,
+		BitDepth:   format.BitDepth,
+	}, nil
+}
+
+// EncodeRaw writes the samples of b in the given format with no header.
+func EncodeRaw(w io.Writer, b *Buffer, format RawFormat) error {
+	if b.Channels <= 0 {
+		return errors.New("raw: buffer has no channels")
+	}
+
+	data, err := encodeSamples(b.Samples, format.Encoding, format.Order, format.Unsigned)
+	if err != nil {
+		return fmt.Errorf("raw: %w", err)
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// ReadRawFile decodes the headerless PCM file at path.
+func ReadRawFile(path string, rate, channels int, format RawFormat) (*Buffer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return DecodeRaw(f, rate, channels, format)
+}
+
+// WriteRawFile encodes b as headerless PCM to the file at path, replacing
+// it if it exists.
+func WriteRawFile(path string, b *Buffer, format RawFormat) error {
+	return writeFile(path, func(w io.Writer) error {
+		return EncodeRaw(w, b, format)
+	})
+}
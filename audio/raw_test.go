@@ -0,0 +1,142 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+)
+
+func TestDecodeRawFixtures(t *testing.T) {
+	tests := []struct {
+		file     string
+		format   string
+		channels int
+		bitDepth int
+	}{
+		{"stereo16be.raw", "pcm16be", 2, 16},
+		{"mono8u.raw", "pcm8u", 1, 8},
+		{"mono32fle.raw", "float32le", 1, 32},
+	}
+
+	for _, tt := range tests {
+		format, err := ParseRawFormat(tt.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := ReadRawFile(filepath.Join("testdata", tt.file), 8000, tt.channels, format)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.file, err)
+		}
+
+		testFixture(t, tt.file, b, 8000, tt.channels, tt.bitDepth)
+	}
+}
+
+func TestRawRoundTrip(t *testing.T) {
+	samples := []float32{0, 0.5, -0.5, 0.25, -1, 0.75, 0.125, -0.25}
+
+	for _, name := range []string{"pcm8", "pcm8u", "pcm16le", "pcm16be", "pcm24le", "pcm24be",
+		"pcm32le", "pcm32be", "float32le", "float32be", "float64le", "float64be"} {
+		format, err := ParseRawFormat(name)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if format.String() != name {
+			t.Errorf("format %s prints as %s", name, format)
+		}
+
+		in := &Buffer{Samples: samples, SampleRate: 8000, Channels: 2}
+		var file bytes.Buffer
+		if err := EncodeRaw(&file, in, format); err != nil {
+			t.Fatalf("%s: EncodeRaw failed: %s", name, err)
+		}
+		if want := len(samples) * max(1, format.BitDepth/8); file.Len() != want {
+			t.Errorf("%s: wrong size. want=%d, got=%d", name, want, file.Len())
+		}
+
+		out, err := DecodeRaw(&file, 8000, 2, format)
+		if err != nil {
+			t.Fatalf("%s: DecodeRaw failed: %s", name, err)
+		}
+		testSamplesEqual(t, name, samples, out.Samples)
+	}
+}
+
+func TestRawByteOrder(t *testing.T) {
+	in := &Buffer{Samples: []float32{0.5}, SampleRate: 8000, Channels: 1}
+
+	tests := []struct {
+		format   string
+		expected []byte
+	}{
+		{"pcm16le", []byte{0x00, 0x40}},
+		{"pcm16be", []byte{0x40, 0x00}},
+		{"pcm24be", []byte{0x40, 0x00, 0x00}},
+		{"pcm8", []byte{0x40}},
+		{"pcm8u", []byte{0xC0}},
+	}
+
+	for _, tt := range tests {
+		format, _ := ParseRawFormat(tt.format)
+		var out bytes.Buffer
+		if err := EncodeRaw(&out, in, format); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out.Bytes(), tt.expected) {
+			t.Errorf("%s: wrong bytes. want=% x, got=% x", tt.format, tt.expected, out.Bytes())
+		}
+	}
+}
+
+func TestRawErrors(t *testing.T) {
+	formats := []struct {
+		name     string
+		expected string
+	}{
+		{"pcm16", `raw format "pcm16" must end in "le" or "be"`},
+		{"pcm12le", `unknown raw format "pcm12le"`},
+		{"pcm8le", `unknown raw format "pcm8le"`},
+	}
+	for _, tt := range formats {
+		if _, err := ParseRawFormat(tt.name); err == nil || err.Error() != tt.expected {
+			t.Errorf("wrong error. want=%q, got=%v", tt.expected, err)
+		}
+	}
+
+	format := RawFormat{Encoding: Encoding{BitDepth: 16}, Order: binary.LittleEndian}
+	decodes := []struct {
+		rate, channels int
+		expected       string
+	}{
+		{0, 1, "raw: sample rate must be positive, got 0"},
+		{8000, 0, "raw: channel count must be positive, got 0"},
+		{8000, 65536, "raw: channel count must be at most 65535, got 65536"},
+		{8000, 1 << 62, "raw: channel count must be at most 65535, got 4611686018427387904"},
+	}
+	for _, tt := range decodes {
+		if _, err := DecodeRaw(bytes.NewReader(nil), tt.rate, tt.channels, format); err == nil || err.Error() != tt.expected {
+			t.Errorf("wrong error. want=%q, got=%v", tt.expected, err)
+		}
+	}
+
+	// a partial frame at the end is dropped
+	b, err := DecodeRaw(bytes.NewReader([]byte{0, 0x40, 0, 0, 1}), 8000, 2, format)
+	if err != nil || len(b.Samples) != 2 {
+		t.Errorf("partial frame should be dropped. got=%v, %v", b, err)
+	}
+}
+
+func testSamplesEqual(t *testing.T, name string, expected, actual []float32) {
+	t.Helper()
+
+	if len(actual) != len(expected) {
+		t.Fatalf("%s: wrong number of samples. want=%d, got=%d", name, len(expected), len(actual))
+	}
+	for i, s := range actual {
+		if s != expected[i] {
+			t.Errorf("%s: wrong sample %d. want=%g, got=%g", name, i, expected[i], s)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+//go:build ignore
+
+// This program writes the AIFF, AIFF-C and raw PCM fixtures that the audio
+// tests decode. The files are assembled byte by byte from the format
+// specifications rather than with the package's encoders, so that the tests
+// check the decoders against an independent source. Run it with go generate
+// in the audio directory; it writes to the testdata directory below it.
+package main
+
+import (
+	"encoding/binary"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+const frames = 16
+
+// 80-bit extended precision sample rates
+var (
+	rate8000  = []byte{0x40, 0x0B, 0xFA, 0, 0, 0, 0, 0, 0, 0}
+	rate44100 = []byte{0x40, 0x0E, 0xAC, 0x44, 0, 0, 0, 0, 0, 0}
+)
+
+// ramp is the value of frame n of channel c in the fixtures, in [-1, 1):
+// a rising ramp in the first channel and a falling one in the second.
+func ramp(n, c int) float64 {
+	v := float64(n) / frames
+	if c == 1 {
+		return -v
+	}
+	return v
+}
+
+func main() {
+	be, le := binary.BigEndian, binary.LittleEndian
+
+	// 16-bit stereo AIFF
+	var pcm16 []byte
+	for n := 0; n < frames; n++ {
+		for c := 0; c < 2; c++ {
+			pcm16 = be.AppendUint16(pcm16, uint16(int16(ramp(n, c)*32768)))
+		}
+	}
+	write("stereo16.aiff", form("AIFF", comm(2, 16, rate44100, ""), ssnd(pcm16)))
+
+	// 12-bit mono AIFF, left-justified in two bytes
+	var pcm12 []byte
+	for n := 0; n < frames; n++ {
+		pcm12 = be.AppendUint16(pcm12, uint16(int16(ramp(n, 0)*2048))<<4)
+	}
+	write("mono12.aiff", form("AIFF", comm(1, 12, rate8000, ""), ssnd(pcm12)))
+
+	// 24-bit mono little-endian AIFF-C
+	var sowt []byte
+	for n := 0; n < frames; n++ {
+		v := uint32(int32(ramp(n, 0) * 8388608))
+		sowt = append(sowt, byte(v), byte(v>>8), byte(v>>16))
+	}
+	write("mono24.aifc", form("AIFC", fver(), comm(1, 24, rate8000, "sowt"), ssnd(sowt)))
+
+	// 32-bit float stereo AIFF-C
+	var fl32 []byte
+	for n := 0; n < frames; n++ {
+		for c := 0; c < 2; c++ {
+			fl32 = be.AppendUint32(fl32, math.Float32bits(float32(ramp(n, c))))
+		}
+	}
+	write("stereo32f.aifc", form("AIFC", fver(), comm(2, 32, rate44100, "fl32"), ssnd(fl32)))
+
+	// headerless 16-bit big-endian stereo and unsigned 8-bit mono
+	write("stereo16be.raw", pcm16)
+
+	var u8 []byte
+	for n := 0; n < frames; n++ {
+		u8 = append(u8, byte(int(ramp(n, 0)*128)+128))
+	}
+	write("mono8u.raw", u8)
+
+	// 32-bit float little-endian mono
+	var f32 []byte
+	for n := 0; n < frames; n++ {
+		f32 = le.AppendUint32(f32, math.Float32bits(float32(ramp(n, 0))))
+	}
+	write("mono32fle.raw", f32)
+}
+
+func form(kind string, chunks ...[]byte) []byte {
+	body := []byte(kind)
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+	return append(binary.BigEndian.AppendUint32([]byte("FORM"), uint32(len(body))), body...)
+}
+
+func chunk(id string, body []byte) []byte {
+	out := append(binary.BigEndian.AppendUint32([]byte(id), uint32(len(body))), body...)
+	if len(body)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+func comm(channels, bits int, rate []byte, compression string) []byte {
+	be := binary.BigEndian
+	body := be.AppendUint16(nil, uint16(channels))
+	body = be.AppendUint32(body, frames)
+	body = be.AppendUint16(body, uint16(bits))
+	body = append(body, rate...)
+	if compression != "" {
+		// compression type and an empty Pascal string for its name
+		body = append(body, compression...)
+		body = append(body, 0, 0)
+	}
+	return chunk("COMM", body)
+}
+
+func fver() []byte {
+	return chunk("FVER", binary.BigEndian.AppendUint32(nil, 0xA2805140))
+}
+
+func ssnd(samples []byte) []byte {
+	// an offset of 4 bytes of padding before the samples, which decoders
+	// must skip
+	body := binary.BigEndian.AppendUint32(nil, 4)
+	body = binary.BigEndian.AppendUint32(body, 0)
+	body = append(body, 0xDE, 0xAD, 0xBE, 0xEF)
+	return chunk("SSND", append(body, samples...))
+}
+
+func write(name string, data []byte) {
+	if err := os.WriteFile(filepath.Join("testdata", name), data, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,208 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	wavFormatPCM        = 0x0001
+	wavFormatIEEEFloat  = 0x0003
+	wavFormatExtensible = 0xFFFE
+)
+
+// The sub-format GUIDs of WAVE_FORMAT_EXTENSIBLE share everything but their
+// first two bytes, which hold the plain format code.
+var wavSubformatSuffix = []byte{
+	0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00,
+	0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71,
+}
+
+// DecodeWAV reads a RIFF/WAVE file holding 8, 16, 24 or 32-bit integer PCM
+// or 32 or 64-bit float samples, with any number of channels.
+func DecodeWAV(r io.Reader) (*Buffer, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 12 || string(data[0:4]) != "RIFF" {
+		return nil, errors.New("wav: not a RIFF file")
+	}
+	if string(data[8:12]) != "WAVE" {
+		return nil, errors.New("wav: RIFF file is not WAVE")
+	}
+
+	var (
+		enc      Encoding
+		channels int
+		rate     int
+		haveFmt  bool
+		samples  []byte
+		haveData bool
+	)
+
+	chunks := data[12:]
+	for len(chunks) >= 8 {
+		id := string(chunks[0:4])
+		size := int(binary.LittleEndian.Uint32(chunks[4:8]))
+		body := chunks[8:]
+
+		// Writers that stream to disk often leave the size of the last chunk
+		// unset, so take whatever is there.
+		if size > len(body) {
+			size = len(body)
+		}
+		body = body[:size]
+
+		switch id {
+		case "fmt ":
+			enc, channels, rate, err = parseWAVFormat(body)
+			if err != nil {
+				return nil, err
+			}
+			haveFmt = true
+		case "data":
+			samples = body
+			haveData = true
+		}
+
+		// chunks are padded to an even length
+		next := 8 + size + size%2
+		if next > len(chunks) {
+			break
+		}
+		chunks = chunks[next:]
+	}
+
+	if !haveFmt {
+		return nil, errors.New("wav: missing fmt chunk")
+	}
+	if !haveData {
+		return nil, errors.New("wav: missing data chunk")
+	}
+
+	frameSize := channels * enc.BitDepth / 8
+	samples = samples[:len(samples)-len(samples)%frameSize]
+
+	decoded, err := decodeSamples(samples, enc, binary.LittleEndian, true)
+	if err != nil {
+		return nil, fmt.Errorf("wav: %w", err)
+	}
+
+	return &Buffer{
+		Samples:    decoded,
+		SampleRate: rate,
+		Channels:   channels,
+		BitDepth:   enc.BitDepth,
+	}, nil
+}
+
+func parseWAVFormat(body []byte) (Encoding, int, int, error) {
+	if len(body) < 16 {
+		return Encoding{}, 0, 0, errors.New("wav: fmt chunk too short")
+	}
+
+	format := binary.LittleEndian.Uint16(body[0:2])
+	channels := int(binary.LittleEndian.Uint16(body[2:4]))
+	rate := int(binary.LittleEndian.Uint32(body[4:8]))
+	bitDepth := int(binary.LittleEndian.Uint16(body[14:16]))
+
+	if format == wavFormatExtensible {
+		if len(body) < 40 {
+			return Encoding{}, 0, 0, errors.New("wav: extensible fmt chunk too short")
+		}
+		if !bytes.Equal(body[26:40], wavSubformatSuffix) {
+			return Encoding{}, 0, 0, errors.New("wav: unsupported extensible sub-format")
+		}
+		format = binary.LittleEndian.Uint16(body[24:26])
+	}
+
+	if channels == 0 {
+		return Encoding{}, 0, 0, errors.New("wav: file has no channels")
+	}
+	if rate == 0 {
+		return Encoding{}, 0, 0, errors.New("wav: sample rate is zero")
+	}
+
+	var enc Encoding
+	switch format {
+	case wavFormatPCM:
+		enc = Encoding{BitDepth: bitDepth}
+	case wavFormatIEEEFloat:
+		enc = Encoding{BitDepth: bitDepth, Float: true}
+	default:
+		return Encoding{}, 0, 0, fmt.Errorf("wav: unsupported codec 0x%04x", format)
+	}
+
+	if _, err := bytesPerSample(enc); err != nil {
+		return Encoding{}, 0, 0, fmt.Errorf("wav: %w", err)
+	}
+
+	return enc, channels, rate, nil
+}
+
+// EncodeWAV writes b as a RIFF/WAVE file with the given encoding. Files with
+// more than two channels use WAVE_FORMAT_EXTENSIBLE.
+func EncodeWAV(w io.Writer, b *Buffer, enc Encoding) error {
+	if b.Channels <= 0 {
+		return errors.New("wav: buffer has no channels")
+	}
+	if b.SampleRate <= 0 {
+		return errors.New("wav: sample rate must be positive")
+	}
+
+	samples, err := encodeSamples(b.Samples, enc, binary.LittleEndian, true)
+	if err != nil {
+		return fmt.Errorf("wav: %w", err)
+	}
+
+	format := uint16(wavFormatPCM)
+	if enc.Float {
+		format = wavFormatIEEEFloat
+	}
+
+	blockAlign := b.Channels * enc.BitDepth / 8
+
+	var fmtChunk bytes.Buffer
+	le := binary.LittleEndian
+	if b.Channels > 2 {
+		binary.Write(&fmtChunk, le, uint16(wavFormatExtensible))
+	} else {
+		binary.Write(&fmtChunk, le, format)
+	}
+	binary.Write(&fmtChunk, le, uint16(b.Channels))
+	binary.Write(&fmtChunk, le, uint32(b.SampleRate))
+	binary.Write(&fmtChunk, le, uint32(b.SampleRate*blockAlign))
+	binary.Write(&fmtChunk, le, uint16(blockAlign))
+	binary.Write(&fmtChunk, le, uint16(enc.BitDepth))
+	if b.Channels > 2 {
+		binary.Write(&fmtChunk, le, uint16(22))           // extension size
+		binary.Write(&fmtChunk, le, uint16(enc.BitDepth)) // valid bits
+		binary.Write(&fmtChunk, le, uint32(0))            // channel mask
+		binary.Write(&fmtChunk, le, format)
+		fmtChunk.Write(wavSubformatSuffix)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("RIFF")
+	binary.Write(&out, le, uint32(4+8+fmtChunk.Len()+8+len(samples)+len(samples)%2))
+	out.WriteString("WAVE")
+
+	out.WriteString("fmt ")
+	binary.Write(&out, le, uint32(fmtChunk.Len()))
+	out.Write(fmtChunk.Bytes())
+
+	out.WriteString("data")
+	binary.Write(&out, le, uint32(len(samples)))
+	out.Write(samples)
+	if len(samples)%2 == 1 {
+		out.WriteByte(0)
+	}
+
+	_, err = w.Write(out.Bytes())
+	return err
+}
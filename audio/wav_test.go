@@ -0,0 +1,168 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func TestWAVRoundTrip(t *testing.T) {
+	samples := []float32{0, 0.5, -0.5, 0.25, -1, 0.75, 0.125, -0.25, 0.5, 0}
+
+	tests := []struct {
+		encoding Encoding
+		channels int
+	}{
+		{Encoding{BitDepth: 8}, 1},
+		{Encoding{BitDepth: 16}, 1},
+		{Encoding{BitDepth: 16}, 2},
+		{Encoding{BitDepth: 24}, 2},
+		{Encoding{BitDepth: 32}, 1},
+		{Encoding{BitDepth: 32, Float: true}, 5},
+		{Encoding{BitDepth: 64, Float: true}, 1},
+	}
+
+	for _, tt := range tests {
+		in := &Buffer{
+			Samples:    samples,
+			SampleRate: 44100,
+			Channels:   tt.channels,
+			BitDepth:   tt.encoding.BitDepth,
+		}
+
+		var file bytes.Buffer
+		err := EncodeWAV(&file, in, tt.encoding)
+		if err != nil {
+			t.Fatalf("%s/%dch: EncodeWAV failed: %s", tt.encoding, tt.channels, err)
+		}
+
+		out, err := DecodeWAV(&file)
+		if err != nil {
+			t.Fatalf("%s/%dch: DecodeWAV failed: %s", tt.encoding, tt.channels, err)
+		}
+
+		if out.SampleRate != 44100 || out.Channels != tt.channels ||
+			out.BitDepth != tt.encoding.BitDepth {
+			t.Errorf("%s/%dch: wrong format. got=%dHz %dch %d-bit",
+				tt.encoding, tt.channels, out.SampleRate, out.Channels, out.BitDepth)
+		}
+
+		frames := len(samples) / tt.channels
+		if len(out.Samples) != frames*tt.channels {
+			t.Fatalf("%s/%dch: wrong number of samples. want=%d, got=%d",
+				tt.encoding, tt.channels, frames*tt.channels, len(out.Samples))
+		}
+
+		for i, s := range out.Samples {
+			if s != samples[i] {
+				t.Errorf("%s/%dch: wrong sample %d. want=%g, got=%g",
+					tt.encoding, tt.channels, i, samples[i], s)
+			}
+		}
+	}
+}
+
+func TestEncodeWAVClipsIntegerSamples(t *testing.T) {
+	in := &Buffer{Samples: []float32{1.5, -1.5}, SampleRate: 8000, Channels: 1}
+
+	var file bytes.Buffer
+	if err := EncodeWAV(&file, in, Encoding{BitDepth: 16}); err != nil {
+		t.Fatalf("EncodeWAV failed: %s", err)
+	}
+
+	data := file.Bytes()[len(file.Bytes())-4:]
+	if got := int16(binary.LittleEndian.Uint16(data[0:2])); got != 32767 {
+		t.Errorf("positive overflow not clipped. got=%d", got)
+	}
+	if got := int16(binary.LittleEndian.Uint16(data[2:4])); got != -32768 {
+		t.Errorf("negative overflow not clipped. got=%d", got)
+	}
+}
+
+func TestDecodeWAVSkipsUnknownChunks(t *testing.T) {
+	file := wavFile(
+		chunk("LIST", []byte{1, 2, 3}), // odd size, padded
+		chunk("fmt ", fmtBody(wavFormatPCM, 1, 8000, 16)),
+		chunk("data", []byte{0x00, 0x40, 0x00, 0xC0}),
+	)
+
+	b, err := DecodeWAV(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("DecodeWAV failed: %s", err)
+	}
+
+	if len(b.Samples) != 2 || b.Samples[0] != 0.5 || b.Samples[1] != -0.5 {
+		t.Errorf("wrong samples. got=%v", b.Samples)
+	}
+}
+
+func TestDecodeWAVErrors(t *testing.T) {
+	tests := []struct {
+		file     []byte
+		expected string
+	}{
+		{[]byte("OggS"), "not a RIFF file"},
+		{append([]byte("RIFF\x00\x00\x00\x00"), []byte("AVI ")...), "RIFF file is not WAVE"},
+		{wavFile(chunk("data", []byte{0, 0})), "missing fmt chunk"},
+		{wavFile(chunk("fmt ", fmtBody(wavFormatPCM, 1, 8000, 16))), "missing data chunk"},
+		{wavFile(chunk("fmt ", []byte{1, 0})), "fmt chunk too short"},
+		{
+			wavFile(chunk("fmt ", fmtBody(0x0055, 1, 8000, 16)), chunk("data", nil)),
+			"unsupported codec 0x0055",
+		},
+		{
+			wavFile(chunk("fmt ", fmtBody(wavFormatPCM, 1, 8000, 12)), chunk("data", nil)),
+			"unsupported sample encoding: pcm12",
+		},
+		{
+			wavFile(chunk("fmt ", fmtBody(wavFormatPCM, 0, 8000, 16)), chunk("data", nil)),
+			"file has no channels",
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := DecodeWAV(bytes.NewReader(tt.file))
+		if err == nil {
+			t.Errorf("expected error %q, got none", tt.expected)
+			continue
+		}
+
+		if !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("wrong error. want=%q, got=%q", tt.expected, err)
+		}
+	}
+}
+
+func wavFile(chunks ...[]byte) []byte {
+	body := []byte("WAVE")
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+
+	out := []byte("RIFF")
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(body)))
+	return append(out, body...)
+}
+
+func chunk(id string, body []byte) []byte {
+	out := []byte(id)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(body)))
+	out = append(out, body...)
+	if len(body)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+func fmtBody(format uint16, channels uint16, rate uint32, bits uint16) []byte {
+	le := binary.LittleEndian
+	blockAlign := channels * bits / 8
+
+	out := le.AppendUint16(nil, format)
+	out = le.AppendUint16(out, channels)
+	out = le.AppendUint32(out, rate)
+	out = le.AppendUint32(out, rate*uint32(blockAlign))
+	out = le.AppendUint16(out, blockAlign)
+	return le.AppendUint16(out, bits)
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"wavy/ast"
+	"wavy/compiler"
+	"wavy/lexer"
+	"wavy/object"
+	"wavy/parser"
+	"wavy/repl"
+	"wavy/token"
+	"wavy/vm"
+)
+
+// Exit codes, so that scripts driving wavy can tell failures apart.
+const (
+	exitOK      = 0
+	exitIO      = 1 // the source file could not be read
+	exitUsage   = 2 // bad command line
+	exitSyntax  = 3 // lexer or parser errors
+	exitCompile = 4 // compiler errors
+	exitRuntime = 5 // vm errors
+)
+
+const usage = `usage: wavy <command> [arguments]
+
+commands:
+  run [-p] <file>      compile and run a program, or run a .vyc file;
+                       -p prints the last value
+  build [-o out] <file.vy>
+                       compile a program to a .vyc file (default: file.vyc)
+  repl                 start an interactive session
+  lex <file.vy>        print the token stream
+  parse <file.vy>      print the parsed program
+  disasm <file>        print the compiled bytecode of a program or .vyc file
+
+exit codes:
+  1  file could not be read or written
+  2  bad command line
+  3  lexer or parser error
+  4  compiler error
+  5  runtime error
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "run":
+		return runFile(args, stdout, stderr)
+	case "build":
+		return buildFile(args, stderr)
+	case "repl":
+		repl.Start(os.Stdin, stdout)
+		return exitOK
+	case "lex":
+		return lexFile(args, stdout, stderr)
+	case "parse":
+		return parseFile(args, stdout, stderr)
+	case "disasm":
+		return disasmFile(args, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "wavy: unknown command %q\n\n", cmd)
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+}
+
+func runFile(args []string, stdout, stderr io.Writer) int {
+	printResult := false
+	if len(args) > 0 && args[0] == "-p" {
+		printResult = true
+		args = args[1:]
+	}
+
+	input, status := readSource("run", args, stderr)
+	if status != exitOK {
+		return status
+	}
+
+	bytecode, status := loadBytecode(args[0], input, stderr)
+	if status != exitOK {
+		return status
+	}
+
+	machine := vm.New(bytecode)
+	if err := machine.Run(); err != nil {
+		printRuntimeError(stderr, err)
+		return exitRuntime
+	}
+
+	if printResult {
+		if result := machine.LastPoppedStackElem(); result != nil {
+			fmt.Fprintln(stdout, result.Inspect())
+		}
+	}
+
+	return exitOK
+}
+
+func buildFile(args []string, stderr io.Writer) int {
+	output := ""
+	if len(args) > 1 && args[0] == "-o" {
+		output = args[1]
+		args = args[2:]
+	}
+
+	input, status := readSource("build", args, stderr)
+	if status != exitOK {
+		return status
+	}
+
+	bytecode, status := compileSource(args[0], input, stderr)
+	if status != exitOK {
+		return status
+	}
+
+	if output == "" {
+		output = strings.TrimSuffix(args[0], filepath.Ext(args[0])) + ".vyc"
+	}
+
+	data, err := bytecode.MarshalBinary()
+	if err == nil {
+		err = os.WriteFile(output, data, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "wavy build: %s\n", err)
+		return exitIO
+	}
+
+	return exitOK
+}
+
+func lexFile(args []string, stdout, stderr io.Writer) int {
+	input, status := readSource("lex", args, stderr)
+	if status != exitOK {
+		return status
+	}
+
+	l := lexer.NewWithFile(input, args[0])
+	for {
+		tok := l.NextToken()
+		fmt.Fprintf(stdout, "<%s, %q>\n", tok.Type, tok.Literal)
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+
+	if printErrors(stderr, "lexer", l.Errors()) {
+		return exitSyntax
+	}
+
+	return exitOK
+}
+
+func parseFile(args []string, stdout, stderr io.Writer) int {
+	input, status := readSource("parse", args, stderr)
+	if status != exitOK {
+		return status
+	}
+
+	program, status := parseSource(args[0], input, stderr)
+	if status != exitOK {
+		return status
+	}
+
+	for _, s := range program.Statements {
+		fmt.Fprintln(stdout, s.String())
+	}
+
+	return exitOK
+}
+
+func disasmFile(args []string, stdout, stderr io.Writer) int {
+	input, status := readSource("disasm", args, stderr)
+	if status != exitOK {
+		return status
+	}
+
+	bytecode, status := loadBytecode(args[0], input, stderr)
+	if status != exitOK {
+		return status
+	}
+
+	fmt.Fprint(stdout, bytecode.Instructions.String())
+
+	for i, constant := range bytecode.Constants {
+		fn, ok := constant.(*object.CompiledFunction)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(stdout, "\nconstant %d: function (params=%d, locals=%d)\n",
+			i, fn.NumParameters, fn.NumLocals)
+		fmt.Fprint(stdout, fn.Instructions.String())
+	}
+
+	return exitOK
+}
+
+func readSource(cmd string, args []string, stderr io.Writer) (string, int) {
+	if len(args) != 1 {
+		fmt.Fprintf(stderr, "wavy %s: expected exactly one source file\n\n", cmd)
+		fmt.Fprint(stderr, usage)
+		return "", exitUsage
+	}
+
+	content, err := os.ReadFile(args[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "wavy %s: %s\n", cmd, err)
+		return "", exitIO
+	}
+
+	return string(content), exitOK
+}
+
+func parseSource(path, input string, stderr io.Writer) (*ast.Program, int) {
+	l := lexer.NewWithFile(input, path)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	failed := printErrors(stderr, "lexer", l.Errors())
+	if printErrors(stderr, "parser", p.Errors()) {
+		failed = true
+	}
+	if failed {
+		return nil, exitSyntax
+	}
+
+	return program, exitOK
+}
+
+func compileSource(path, input string, stderr io.Writer) (*compiler.Bytecode, int) {
+	program, status := parseSource(path, input, stderr)
+	if status != exitOK {
+		return nil, status
+	}
+
+	comp := compiler.New()
+	if err := comp.Compile(program); err != nil {
+		fmt.Fprintf(stderr, "compiler error: %s\n", err)
+		return nil, exitCompile
+	}
+
+	return comp.Bytecode(), exitOK
+}
+
+// loadBytecode decodes input if it is a .vyc file and compiles it otherwise.
+func loadBytecode(path, input string, stderr io.Writer) (*compiler.Bytecode, int) {
+	if !compiler.IsVyc([]byte(input)) {
+		return compileSource(path, input, stderr)
+	}
+
+	bytecode, err := compiler.UnmarshalBytecode([]byte(input))
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %s\n", path, err)
+		return nil, exitIO
+	}
+
+	return bytecode, exitOK
+}
+
+// printRuntimeError prints the VM's traceback, if it has one, followed by
+// the error itself.
+func printRuntimeError(stderr io.Writer, err error) {
+	var runtimeErr *vm.RuntimeError
+	if errors.As(err, &runtimeErr) {
+		fmt.Fprint(stderr, runtimeErr.Traceback())
+	}
+	fmt.Fprintf(stderr, "runtime error: %s\n", err)
+}
+
+func printErrors(stderr io.Writer, phase string, errors []string) bool {
+	if len(errors) == 0 {
+		return false
+	}
+
+	fmt.Fprintf(stderr, "%s has %d error(s):\n", phase, len(errors))
+	for _, msg := range errors {
+		fmt.Fprintf(stderr, "\t%s\n", msg)
+	}
+
+	return true
+}
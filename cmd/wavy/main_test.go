@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCommands(t *testing.T) {
+	tests := []struct {
+		args           []string
+		source         string
+		expectedStatus int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			args:           []string{"run", "-p"},
+			source:         `let a = 1; a + 2`,
+			expectedStatus: exitOK,
+			expectedStdout: "3\n",
+		},
+		{
+			args:           []string{"lex"},
+			source:         `let x = 5;`,
+			expectedStatus: exitOK,
+			expectedStdout: "<LET, \"let\">\n<IDENT, \"x\">\n<=, \"=\">\n<INT, \"5\">\n<;, \";\">\n<EOF, \"\">\n",
+		},
+		{
+			args:           []string{"parse"},
+			source:         `let x = 1 + 2 * 3;`,
+			expectedStatus: exitOK,
+			expectedStdout: "let x = (1 + (2 * 3));\n",
+		},
+		{
+			args:           []string{"disasm"},
+			source:         `1 + 2`,
+			expectedStatus: exitOK,
+			expectedStdout: "0000 OpConstant 0\n0003 OpConstant 1\n0006 OpAdd\n0007 OpPop\n",
+		},
+		{
+			args:           []string{"run"},
+			source:         `let x = ;`,
+			expectedStatus: exitSyntax,
+			expectedStderr: "parser has 1 error(s):",
+		},
+		{
+			args:           []string{"run"},
+			source:         `y`,
+			expectedStatus: exitCompile,
+			expectedStderr: "compiler error: undefined variable y",
+		},
+		{
+			args:           []string{"run"},
+			source:         `1 + "a"`,
+			expectedStatus: exitRuntime,
+			expectedStderr: "runtime error: unsupported types for binary operation: INTEGER STRING",
+		},
+		{
+			args:           []string{"run"},
+			source:         "let f = fn() {\n  1 + \"a\"\n};\nf()",
+			expectedStatus: exitRuntime,
+			expectedStderr: "traceback (most recent call last):\n  in <main> at ",
+		},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "main.vy")
+		if err := os.WriteFile(path, []byte(tt.source), 0o644); err != nil {
+			t.Fatalf("could not write source: %s", err)
+		}
+
+		var stdout, stderr bytes.Buffer
+		status := run(append(tt.args, path), &stdout, &stderr)
+
+		if status != tt.expectedStatus {
+			t.Errorf("%v: wrong exit status. want=%d, got=%d (stderr=%q)",
+				tt.args, tt.expectedStatus, status, stderr.String())
+		}
+
+		if tt.expectedStdout != "" && stdout.String() != tt.expectedStdout {
+			t.Errorf("%v: wrong stdout.\nwant=%q\ngot =%q",
+				tt.args, tt.expectedStdout, stdout.String())
+		}
+
+		if !strings.Contains(stderr.String(), tt.expectedStderr) {
+			t.Errorf("%v: stderr does not contain %q. got=%q",
+				tt.args, tt.expectedStderr, stderr.String())
+		}
+	}
+}
+
+func TestBuildAndRunVyc(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "main.vy")
+	compiled := filepath.Join(dir, "main.vyc")
+
+	err := os.WriteFile(source, []byte(`let double = fn(x) { x * 2 }; double(21)`), 0o644)
+	if err != nil {
+		t.Fatalf("could not write source: %s", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{"build", source}, &stdout, &stderr); status != exitOK {
+		t.Fatalf("build failed with status %d: %s", status, stderr.String())
+	}
+
+	stdout.Reset()
+	if status := run([]string{"run", "-p", compiled}, &stdout, &stderr); status != exitOK {
+		t.Fatalf("run failed with status %d: %s", status, stderr.String())
+	}
+	if stdout.String() != "42\n" {
+		t.Errorf("wrong output. want=%q, got=%q", "42\n", stdout.String())
+	}
+
+	other := filepath.Join(dir, "other.vyc")
+	if status := run([]string{"build", "-o", other, source}, &stdout, &stderr); status != exitOK {
+		t.Fatalf("build -o failed with status %d: %s", status, stderr.String())
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("build -o did not write %s: %s", other, err)
+	}
+
+	data, _ := os.ReadFile(compiled)
+	os.WriteFile(compiled, data[:len(data)-1], 0o644)
+	stderr.Reset()
+	if status := run([]string{"run", compiled}, &stdout, &stderr); status != exitIO {
+		t.Errorf("truncated .vyc: wrong exit status. want=%d, got=%d", exitIO, status)
+	}
+}
+
+func TestRunUsageErrors(t *testing.T) {
+	tests := []struct {
+		args           []string
+		expectedStatus int
+	}{
+		{[]string{}, exitUsage},
+		{[]string{"frobnicate"}, exitUsage},
+		{[]string{"run"}, exitUsage},
+		{[]string{"run", filepath.Join(t.TempDir(), "missing.vy")}, exitIO},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		status := run(tt.args, &stdout, &stderr)
+		if status != tt.expectedStatus {
+			t.Errorf("%v: wrong exit status. want=%d, got=%d",
+				tt.args, tt.expectedStatus, status)
+		}
+	}
+}
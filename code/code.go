@@ -97,6 +97,14 @@ const (
 	OpClosure
 
 	OpGetFree
+
+	OpIter
+	OpIterNext
+
+	OpMod
+	OpGreaterThanOrEqual
+
+	OpSlice
 )
 
 type Definition struct {
@@ -150,6 +158,14 @@ var definitions = map[Opcode]*Definition{
 	OpClosure: {"OpClosure", []int{2, 1}},
 
 	OpGetFree: {"OpGetFree", []int{1}},
+
+	OpIter:     {"OpIter", []int{}},
+	OpIterNext: {"OpIterNext", []int{2}},
+
+	OpMod:                {"OpMod", []int{}},
+	OpGreaterThanOrEqual: {"OpGreaterThanOrEqual", []int{}},
+
+	OpSlice: {"OpSlice", []int{}},
 }
 
 func Lookup(op byte) (*Definition, error) {
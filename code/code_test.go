@@ -1,6 +1,9 @@
 package code
 
-import "testing"
+import (
+	"testing"
+	"wavy/token"
+)
 
 func TestMake(t *testing.T) {
 	tests := []struct {
@@ -89,3 +92,39 @@ func TestReadOperands(t *testing.T) {
 		}
 	}
 }
+
+func TestLineTableLookup(t *testing.T) {
+	lines := LineTable{
+		{Offset: 0, Pos: token.Position{Line: 1, Column: 1}},
+		{Offset: 3, Pos: token.Position{Line: 1, Column: 5}},
+		{Offset: 7, Pos: token.Position{Line: 2, Column: 1}},
+	}
+
+	tests := []struct {
+		offset   int
+		expected token.Position
+	}{
+		{0, token.Position{Line: 1, Column: 1}},
+		{2, token.Position{Line: 1, Column: 1}},
+		{3, token.Position{Line: 1, Column: 5}},
+		{6, token.Position{Line: 1, Column: 5}},
+		{7, token.Position{Line: 2, Column: 1}},
+		{100, token.Position{Line: 2, Column: 1}},
+	}
+
+	for _, tt := range tests {
+		pos, ok := lines.Lookup(tt.offset)
+		if !ok {
+			t.Errorf("no position for offset %d", tt.offset)
+			continue
+		}
+		if pos != tt.expected {
+			t.Errorf("wrong position for offset %d. want=%s, got=%s",
+				tt.offset, tt.expected, pos)
+		}
+	}
+
+	if _, ok := (LineTable{}).Lookup(0); ok {
+		t.Errorf("empty table returned a position")
+	}
+}
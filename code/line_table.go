@@ -0,0 +1,27 @@
+package code
+
+import (
+	"sort"
+	"wavy/token"
+)
+
+// LineEntry records that the instructions from Offset up to the next entry
+// were compiled from source at Pos.
+type LineEntry struct {
+	Offset int
+	Pos    token.Position
+}
+
+// LineTable maps instruction offsets back to source positions. Entries are
+// sorted by Offset.
+type LineTable []LineEntry
+
+// Lookup returns the source position of the instruction containing offset.
+func (lt LineTable) Lookup(offset int) (token.Position, bool) {
+	i := sort.Search(len(lt), func(i int) bool { return lt[i].Offset > offset })
+	if i == 0 {
+		return token.Position{}, false
+	}
+
+	return lt[i-1].Pos, true
+}
@@ -6,6 +6,7 @@ import (
 	"wavy/ast"
 	"wavy/code"
 	"wavy/object"
+	"wavy/token"
 )
 
 type Compiler struct {
@@ -15,6 +16,9 @@ type Compiler struct {
 
 	scopes     []CompilationScope
 	scopeIndex int
+
+	// position of the node being compiled, recorded in the line table
+	pos token.Position
 }
 
 func New() *Compiler {
@@ -46,6 +50,12 @@ func NewWithState(s *SymbolTable, constants []object.Object) *Compiler {
 }
 
 func (c *Compiler) Compile(node ast.Node) error {
+	if pos := node.Pos(); pos.IsValid() {
+		outer := c.pos
+		c.pos = pos
+		defer func() { c.pos = outer }()
+	}
+
 	switch node := node.(type) {
 	case *ast.Program:
 		for _, s := range node.Statements {
@@ -63,7 +73,7 @@ func (c *Compiler) Compile(node ast.Node) error {
 		c.emit(code.OpPop)
 
 	case *ast.InfixExpression:
-		if node.Operator == "<" {
+		if node.Operator == "<" || node.Operator == "<=" {
 			err := c.Compile(node.Right)
 			if err != nil {
 				return err
@@ -73,10 +83,19 @@ func (c *Compiler) Compile(node ast.Node) error {
 			if err != nil {
 				return err
 			}
-			c.emit(code.OpGreaterThan)
+
+			if node.Operator == "<" {
+				c.emit(code.OpGreaterThan)
+			} else {
+				c.emit(code.OpGreaterThanOrEqual)
+			}
 			return nil
 		}
 
+		if node.Operator == "&&" || node.Operator == "||" {
+			return c.compileLogicalExpression(node)
+		}
+
 		err := c.Compile(node.Left)
 		if err != nil {
 			return err
@@ -96,20 +115,28 @@ func (c *Compiler) Compile(node ast.Node) error {
 			c.emit(code.OpMul)
 		case "/":
 			c.emit(code.OpDiv)
+		case "%":
+			c.emit(code.OpMod)
 		case ">":
 			c.emit(code.OpGreaterThan)
+		case ">=":
+			c.emit(code.OpGreaterThanOrEqual)
 		case "==":
 			c.emit(code.OpEqual)
 		case "!=":
 			c.emit(code.OpNotEqual)
 		default:
-			return fmt.Errorf("unknown operator %s", node.Operator)
+			return c.errorf("unknown operator %s", node.Operator)
 		}
 
 	case *ast.IntegerLiteral:
 		integer := &object.Integer{Value: node.Value}
 		c.emit(code.OpConstant, c.addConstant(integer))
 
+	case *ast.FloatLiteral:
+		float := &object.Float{Value: node.Value}
+		c.emit(code.OpConstant, c.addConstant(float))
+
 	case *ast.Boolean:
 		if node.Value {
 			c.emit(code.OpTrue)
@@ -129,7 +156,7 @@ func (c *Compiler) Compile(node ast.Node) error {
 		case "-":
 			c.emit(code.OpMinus)
 		default:
-			return fmt.Errorf("unknown operator %s", node.Operator)
+			return c.errorf("unknown operator %s", node.Operator)
 		}
 
 	case *ast.IfExpression:
@@ -148,6 +175,10 @@ func (c *Compiler) Compile(node ast.Node) error {
 
 		if c.lastInstructionIs(code.OpPop) {
 			c.removeLastPop()
+		} else {
+			// the block ends in a statement, such as a loop, that leaves
+			// nothing on the stack
+			c.emit(code.OpNull)
 		}
 
 		// Emit an `OpJump` with a bogus value
@@ -166,6 +197,8 @@ func (c *Compiler) Compile(node ast.Node) error {
 
 			if c.lastInstructionIs(code.OpPop) {
 				c.removeLastPop()
+			} else {
+				c.emit(code.OpNull)
 			}
 		}
 
@@ -187,16 +220,76 @@ func (c *Compiler) Compile(node ast.Node) error {
 			return err
 		}
 
-		if symbol.Scope == GlobalScope {
-			c.emit(code.OpSetGlobal, symbol.Index)
-		} else {
-			c.emit(code.OpSetLocal, symbol.Index)
+		c.storeSymbol(symbol)
+
+	case *ast.WhileStatement:
+		loopStart := len(c.currentInstructions())
+
+		err := c.Compile(node.Condition)
+		if err != nil {
+			return err
+		}
+
+		// Emit an `OpJumpNotTruthy` with a bogus value
+		jumpNotTruthyPos := c.emit(code.OpJumpNotTruthy, 9999)
+
+		err = c.compileLoopBody(node.Body, loopStart)
+		if err != nil {
+			return err
+		}
+
+		c.changeOperand(jumpNotTruthyPos, len(c.currentInstructions()))
+
+	case *ast.ForStatement:
+		err := c.Compile(node.Iterable)
+		if err != nil {
+			return err
+		}
+
+		c.emit(code.OpIter)
+
+		// The iterator lives in a slot that user code cannot name, since
+		// identifiers cannot contain '$'.
+		iterator := c.symbolTable.Define(fmt.Sprintf("$iter%d", len(c.currentLoops())))
+		c.storeSymbol(iterator)
+
+		loopStart := len(c.currentInstructions())
+		c.loadSymbol(iterator)
+
+		// Emit an `OpIterNext` with a bogus value
+		iterNextPos := c.emit(code.OpIterNext, 9999)
+
+		variable := c.symbolTable.Define(node.Variable.Value)
+		c.storeSymbol(variable)
+
+		err = c.compileLoopBody(node.Body, loopStart)
+		if err != nil {
+			return err
 		}
 
+		c.changeOperand(iterNextPos, len(c.currentInstructions()))
+
+	case *ast.BreakStatement:
+		loop := c.innermostLoop()
+		if loop == nil {
+			return c.errorf("break outside loop")
+		}
+
+		// Emit an `OpJump` with a bogus value, patched when the loop ends
+		loop.breakJumps = append(loop.breakJumps, c.emit(code.OpJump, 9999))
+
+	case *ast.ContinueStatement:
+		loop := c.innermostLoop()
+		if loop == nil {
+			return c.errorf("continue outside loop")
+		}
+
+		c.emit(code.OpJump, loop.start)
+
 	case *ast.Identifier:
 		symbol, ok := c.symbolTable.Resolve(node.Value)
 		if !ok {
-			return fmt.Errorf("undefined variable %s", node.Value)
+			return c.errorf("undefined variable %s", node.Value)
 		}
 
 		c.loadSymbol(symbol)
@@ -250,6 +343,27 @@ func (c *Compiler) Compile(node ast.Node) error {
 
 		c.emit(code.OpIndex)
 
+	case *ast.SliceExpression:
+		err := c.Compile(node.Left)
+		if err != nil {
+			return err
+		}
+
+		// omitted bounds are pushed as null
+		for _, bound := range []ast.Expression{node.Start, node.End} {
+			if bound == nil {
+				c.emit(code.OpNull)
+				continue
+			}
+
+			err := c.Compile(bound)
+			if err != nil {
+				return err
+			}
+		}
+
+		c.emit(code.OpSlice)
+
 	case *ast.FunctionLiteral:
 		c.enterScope()
 
@@ -271,6 +385,7 @@ func (c *Compiler) Compile(node ast.Node) error {
 
 		freeSymbols := c.symbolTable.FreeSymbols
 		numLocals := c.symbolTable.numDefinitions
+		lines := c.scopes[c.scopeIndex].lines
 		instructions := c.leaveScope()
 
 		for _, s := range freeSymbols {
@@ -281,6 +396,8 @@ func (c *Compiler) Compile(node ast.Node) error {
 			Instructions:  instructions,
 			NumLocals:     numLocals,
 			NumParameters: len(node.Parameters),
+			Lines:         lines,
+			Name:          node.Name,
 		}
 
 		fnIndex := c.addConstant(compiledFn)
@@ -314,11 +431,116 @@ func (c *Compiler) Compile(node ast.Node) error {
 	return nil
 }
 
+// compileLoopBody compiles body followed by a jump back to start, the
+// position `continue` jumps to, and patches every `break` in body to jump
+// past the loop.
+func (c *Compiler) compileLoopBody(body *ast.BlockStatement, start int) error {
+	loops := &c.scopes[c.scopeIndex].loops
+	*loops = append(*loops, &loopScope{start: start})
+
+	err := c.Compile(body)
+	if err != nil {
+		return err
+	}
+
+	c.emit(code.OpJump, start)
+
+	loop := c.innermostLoop()
+	*loops = (*loops)[:len(*loops)-1]
+
+	afterLoopPos := len(c.currentInstructions())
+	for _, pos := range loop.breakJumps {
+		c.changeOperand(pos, afterLoopPos)
+	}
+
+	return nil
+}
+
+func (c *Compiler) currentLoops() []*loopScope {
+	return c.scopes[c.scopeIndex].loops
+}
+
+// innermostLoop returns the loop being compiled in the current function, or
+// nil if there is none; loops do not extend into nested functions.
+func (c *Compiler) innermostLoop() *loopScope {
+	loops := c.currentLoops()
+	if len(loops) == 0 {
+		return nil
+	}
+	return loops[len(loops)-1]
+}
+
 func (c *Compiler) Bytecode() *Bytecode {
 	return &Bytecode{
 		Instructions: c.currentInstructions(),
 		Constants:    c.constants,
+		Lines:        c.scopes[c.scopeIndex].lines,
+	}
+}
+
+// compileLogicalExpression compiles && and || so that the right operand is
+// only evaluated when the left one does not already decide the result. Both
+// produce true or false.
+//
+//	a && b                      a || b
+//	  a                           a
+//	  OpJumpNotTruthy false       OpJumpNotTruthy right
+//	  b                           OpTrue
+//	  OpJumpNotTruthy false       OpJump end
+//	  OpTrue                    right:
+//	  OpJump end                  b
+//	false:                        OpJumpNotTruthy false
+//	  OpFalse                     OpTrue
+//	end:                          OpJump end
+//	                            false:
+//	                              OpFalse
+//	                            end:
+func (c *Compiler) compileLogicalExpression(node *ast.InfixExpression) error {
+	err := c.Compile(node.Left)
+	if err != nil {
+		return err
+	}
+
+	var jumpsToEnd []int
+
+	leftJump := c.emit(code.OpJumpNotTruthy, 9999)
+	if node.Operator == "||" {
+		c.emit(code.OpTrue)
+		jumpsToEnd = append(jumpsToEnd, c.emit(code.OpJump, 9999))
+		c.changeOperand(leftJump, len(c.currentInstructions()))
 	}
+
+	err = c.Compile(node.Right)
+	if err != nil {
+		return err
+	}
+
+	rightJump := c.emit(code.OpJumpNotTruthy, 9999)
+	c.emit(code.OpTrue)
+	jumpsToEnd = append(jumpsToEnd, c.emit(code.OpJump, 9999))
+
+	falsePos := len(c.currentInstructions())
+	c.changeOperand(rightJump, falsePos)
+	if node.Operator == "&&" {
+		c.changeOperand(leftJump, falsePos)
+	}
+	c.emit(code.OpFalse)
+
+	endPos := len(c.currentInstructions())
+	for _, jump := range jumpsToEnd {
+		c.changeOperand(jump, endPos)
+	}
+
+	return nil
+}
+
+// errorf returns an error located at the node being compiled.
+func (c *Compiler) errorf(format string, a ...interface{}) error {
+	msg := fmt.Sprintf(format, a...)
+	if !c.pos.IsValid() {
+		return fmt.Errorf("%s", msg)
+	}
+	return fmt.Errorf("%s at %s", msg, c.pos)
 }
 
 func (c *Compiler) addConstant(obj object.Object) int {
@@ -341,6 +563,12 @@ func (c *Compiler) addInstruction(ins []byte) int {
 
 	c.scopes[c.scopeIndex].instructions = updatedInstructions
 
+	lines := c.scopes[c.scopeIndex].lines
+	if c.pos.IsValid() && (len(lines) == 0 || lines[len(lines)-1].Pos != c.pos) {
+		c.scopes[c.scopeIndex].lines = append(lines,
+			code.LineEntry{Offset: posNewInstruction, Pos: c.pos})
+	}
+
 	return posNewInstruction
 }
 
@@ -369,6 +597,12 @@ func (c *Compiler) removeLastPop() {
 
 	c.scopes[c.scopeIndex].instructions = new
 	c.scopes[c.scopeIndex].lastInstruction = previous
+
+	lines := c.scopes[c.scopeIndex].lines
+	for len(lines) > 0 && lines[len(lines)-1].Offset >= last.Position {
+		lines = lines[:len(lines)-1]
+	}
+	c.scopes[c.scopeIndex].lines = lines
 }
 
 func (c *Compiler) replaceInstruction(pos int, newInstruction []byte) {
@@ -420,6 +654,14 @@ func (c *Compiler) replaceLastPopWithReturn() {
 	c.scopes[c.scopeIndex].lastInstruction.Opcode = code.OpReturnValue
 }
 
+func (c *Compiler) storeSymbol(s Symbol) {
+	if s.Scope == GlobalScope {
+		c.emit(code.OpSetGlobal, s.Index)
+	} else {
+		c.emit(code.OpSetLocal, s.Index)
+	}
+}
+
 func (c *Compiler) loadSymbol(s Symbol) {
 	switch s.Scope {
 	case GlobalScope:
@@ -436,6 +678,7 @@ func (c *Compiler) loadSymbol(s Symbol) {
 type Bytecode struct {
 	Instructions code.Instructions
 	Constants    []object.Object
+	Lines        code.LineTable
 }
 
 type EmittedInstruction struct {
@@ -447,4 +690,11 @@ type CompilationScope struct {
 	instructions        code.Instructions
 	lastInstruction     EmittedInstruction
 	previousInstruction EmittedInstruction
+	loops               []*loopScope
+	lines               code.LineTable
+}
+
+type loopScope struct {
+	start      int
+	breakJumps []int
 }
@@ -36,6 +36,8 @@ func NewSymbolTable() *SymbolTable {
 	return &SymbolTable{store: s, FreeSymbols: free}
 }
 
+// Define binds name in this scope. Defining a name that is already bound in
+// the same scope reuses its slot, so `let x = x + 1;` updates x in place.
 func (s *SymbolTable) Define(name string) Symbol {
 	symbol := Symbol{Name: name, Index: s.numDefinitions}
 	if s.Outer == nil {
@@ -44,6 +46,10 @@ func (s *SymbolTable) Define(name string) Symbol {
 		symbol.Scope = LocalScope
 	}
 
+	if existing, ok := s.store[name]; ok && existing.Scope == symbol.Scope {
+		return existing
+	}
+
 	s.store[name] = symbol
 	s.numDefinitions++
 	return symbol
@@ -49,6 +49,32 @@ func TestDefine(t *testing.T) {
 	}
 }
 
+func TestRedefine(t *testing.T) {
+	global := NewSymbolTable()
+	global.DefineBuiltin(0, "len")
+
+	a := global.Define("a")
+	global.Define("b")
+
+	again := global.Define("a")
+	if again != a {
+		t.Errorf("redefining a did not reuse its symbol. want=%+v, got=%+v", a, again)
+	}
+
+	shadow := global.Define("len")
+	expected := Symbol{Name: "len", Scope: GlobalScope, Index: 2}
+	if shadow != expected {
+		t.Errorf("expected len=%+v, got=%+v", expected, shadow)
+	}
+
+	local := NewEnclosedSymbolTable(global)
+	localA := local.Define("a")
+	expected = Symbol{Name: "a", Scope: LocalScope, Index: 0}
+	if localA != expected {
+		t.Errorf("expected a=%+v, got=%+v", expected, localA)
+	}
+}
+
 func TestResolveGlobal(t *testing.T) {
 	global := NewSymbolTable()
 	global.Define("a")
@@ -0,0 +1,475 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"wavy/code"
+	"wavy/object"
+	"wavy/token"
+)
+
+// A .vyc file holds compiled bytecode so that a program can be run without
+// its source. It starts with a fixed header:
+//
+//	magic     4 bytes  "WVYC"
+//	version   uint16
+//	builtins  uint16   number of builtins the program was compiled against
+//	length    uint32   length of the payload
+//	checksum  uint32   CRC-32 (IEEE) of the payload
+//
+// All fixed-size integers are big-endian, like instruction operands. The
+// payload is a table of source file names, the main instructions with their
+// line table, and the constant pool. Variable-size integers use
+// encoding/binary's varint encoding.
+const (
+	VycMagic   = "WVYC"
+	VycVersion = 1
+
+	vycHeaderSize = 16
+)
+
+// Constant tags in the payload.
+const (
+	vycInteger  byte = 'i'
+	vycFloat    byte = 'f'
+	vycString   byte = 's'
+	vycFunction byte = 'c'
+)
+
+var ErrNotVyc = errors.New("vyc: not a compiled wavy file")
+
+// IsVyc reports whether data starts with the .vyc magic number.
+func IsVyc(data []byte) bool {
+	return len(data) >= len(VycMagic) && string(data[:len(VycMagic)]) == VycMagic
+}
+
+// WriteTo writes b in the .vyc format.
+func (b *Bytecode) WriteTo(w io.Writer) (int64, error) {
+	data, err := b.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// MarshalBinary encodes b in the .vyc format.
+func (b *Bytecode) MarshalBinary() ([]byte, error) {
+	enc := &vycEncoder{fileIndex: map[string]int{}}
+
+	// The file table comes first in the payload but is only known once the
+	// rest has been encoded.
+	var body bytes.Buffer
+	enc.out = &body
+	enc.instructions(b.Instructions, b.Lines)
+	enc.uvarint(len(b.Constants))
+	for _, constant := range b.Constants {
+		if err := enc.constant(constant); err != nil {
+			return nil, err
+		}
+	}
+
+	var payload bytes.Buffer
+	enc.out = &payload
+	enc.uvarint(len(enc.files))
+	for _, file := range enc.files {
+		enc.string(file)
+	}
+	payload.Write(body.Bytes())
+
+	var out bytes.Buffer
+	out.WriteString(VycMagic)
+	binary.Write(&out, binary.BigEndian, uint16(VycVersion))
+	binary.Write(&out, binary.BigEndian, uint16(len(object.Builtins)))
+	binary.Write(&out, binary.BigEndian, uint32(payload.Len()))
+	binary.Write(&out, binary.BigEndian, crc32.ChecksumIEEE(payload.Bytes()))
+	out.Write(payload.Bytes())
+
+	return out.Bytes(), nil
+}
+
+// ReadBytecode decodes bytecode written by WriteTo.
+func ReadBytecode(r io.Reader) (*Bytecode, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return UnmarshalBytecode(data)
+}
+
+// UnmarshalBytecode decodes bytecode in the .vyc format.
+func UnmarshalBytecode(data []byte) (*Bytecode, error) {
+	if !IsVyc(data) {
+		return nil, ErrNotVyc
+	}
+	if len(data) < vycHeaderSize {
+		return nil, errors.New("vyc: truncated header")
+	}
+
+	version := binary.BigEndian.Uint16(data[4:6])
+	builtins := int(binary.BigEndian.Uint16(data[6:8]))
+	length := int(binary.BigEndian.Uint32(data[8:12]))
+	checksum := binary.BigEndian.Uint32(data[12:16])
+
+	if version != VycVersion {
+		return nil, fmt.Errorf("vyc: unsupported version %d, want %d", version, VycVersion)
+	}
+	if builtins > len(object.Builtins) {
+		return nil, fmt.Errorf("vyc: compiled against %d builtins, this build has %d",
+			builtins, len(object.Builtins))
+	}
+
+	payload := data[vycHeaderSize:]
+	if len(payload) != length {
+		return nil, fmt.Errorf("vyc: payload is %d bytes, header says %d", len(payload), length)
+	}
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return nil, errors.New("vyc: checksum mismatch")
+	}
+
+	dec := &vycDecoder{data: payload}
+
+	numFiles := dec.uvarint()
+	for i := 0; i < numFiles && dec.err == nil; i++ {
+		dec.files = append(dec.files, dec.string())
+	}
+
+	ins, lines := dec.instructions()
+
+	numConstants := dec.uvarint()
+	constants := []object.Object{}
+	for i := 0; i < numConstants && dec.err == nil; i++ {
+		constants = append(constants, dec.constant())
+	}
+
+	if dec.err == nil && len(dec.data) != 0 {
+		dec.err = fmt.Errorf("%d trailing bytes", len(dec.data))
+	}
+	if dec.err == nil {
+		dec.err = checkReferences(ins, constants, builtins)
+	}
+	if dec.err != nil {
+		return nil, fmt.Errorf("vyc: %w", dec.err)
+	}
+
+	return &Bytecode{
+		Instructions: ins,
+		Constants:    constants,
+		Lines:        lines,
+	}, nil
+}
+
+type vycEncoder struct {
+	out *bytes.Buffer
+
+	files     []string
+	fileIndex map[string]int
+}
+
+func (e *vycEncoder) uvarint(n int) {
+	e.out.Write(binary.AppendUvarint(nil, uint64(n)))
+}
+
+func (e *vycEncoder) string(s string) {
+	e.uvarint(len(s))
+	e.out.WriteString(s)
+}
+
+func (e *vycEncoder) instructions(ins code.Instructions, lines code.LineTable) {
+	e.uvarint(len(ins))
+	e.out.Write(ins)
+
+	e.uvarint(len(lines))
+	for _, entry := range lines {
+		file, ok := e.fileIndex[entry.Pos.File]
+		if !ok {
+			file = len(e.files)
+			e.files = append(e.files, entry.Pos.File)
+			e.fileIndex[entry.Pos.File] = file
+		}
+
+		e.uvarint(entry.Offset)
+		e.uvarint(file)
+		e.uvarint(entry.Pos.Line)
+		e.uvarint(entry.Pos.Column)
+	}
+}
+
+func (e *vycEncoder) constant(obj object.Object) error {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		e.out.WriteByte(vycInteger)
+		e.out.Write(binary.AppendVarint(nil, obj.Value))
+	case *object.Float:
+		e.out.WriteByte(vycFloat)
+		binary.Write(e.out, binary.BigEndian, math.Float64bits(obj.Value))
+	case *object.String:
+		e.out.WriteByte(vycString)
+		e.string(obj.Value)
+	case *object.CompiledFunction:
+		e.out.WriteByte(vycFunction)
+		e.string(obj.Name)
+		e.uvarint(obj.NumLocals)
+		e.uvarint(obj.NumParameters)
+		e.instructions(obj.Instructions, obj.Lines)
+	default:
+		return fmt.Errorf("vyc: cannot serialize constant of type %s", obj.Type())
+	}
+
+	return nil
+}
+
+// vycDecoder reads from data, remembering the first error so that callers
+// can check once after a sequence of reads.
+type vycDecoder struct {
+	data  []byte
+	files []string
+	err   error
+}
+
+var errTruncated = errors.New("truncated payload")
+
+func (d *vycDecoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.data) {
+		d.err = errTruncated
+		return nil
+	}
+
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *vycDecoder) uvarint() int {
+	if d.err != nil {
+		return 0
+	}
+
+	n, size := binary.Uvarint(d.data)
+	if size <= 0 || n > math.MaxInt32 {
+		d.err = errTruncated
+		return 0
+	}
+
+	d.data = d.data[size:]
+	return int(n)
+}
+
+func (d *vycDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+
+	n, size := binary.Varint(d.data)
+	if size <= 0 {
+		d.err = errTruncated
+		return 0
+	}
+
+	d.data = d.data[size:]
+	return n
+}
+
+func (d *vycDecoder) string() string {
+	return string(d.next(d.uvarint()))
+}
+
+func (d *vycDecoder) instructions() (code.Instructions, code.LineTable) {
+	ins := code.Instructions(append([]byte{}, d.next(d.uvarint())...))
+	if d.err == nil {
+		d.err = checkInstructions(ins)
+	}
+
+	numLines := d.uvarint()
+	var lines code.LineTable
+	for i := 0; i < numLines && d.err == nil; i++ {
+		offset := d.uvarint()
+		file := d.uvarint()
+		line := d.uvarint()
+		column := d.uvarint()
+
+		if d.err == nil && file >= len(d.files) {
+			d.err = fmt.Errorf("file index %d out of range", file)
+		}
+		if d.err != nil {
+			break
+		}
+
+		lines = append(lines, code.LineEntry{
+			Offset: offset,
+			Pos:    token.Position{File: d.files[file], Line: line, Column: column},
+		})
+	}
+
+	return ins, lines
+}
+
+// checkInstructions makes sure every opcode is known and has all of its
+// operands. checkReferences then checks what the operands refer to.
+func checkInstructions(ins code.Instructions) error {
+	for i := 0; i < len(ins); {
+		def, err := code.Lookup(ins[i])
+		if err != nil {
+			return fmt.Errorf("offset %d: %w", i, err)
+		}
+
+		width := 0
+		for _, w := range def.OperandWidths {
+			width += w
+		}
+		if i+1+width > len(ins) {
+			return fmt.Errorf("offset %d: %s is missing operands", i, def.Name)
+		}
+
+		i += 1 + width
+	}
+
+	return nil
+}
+
+// checkReferences makes sure the operands of the main instructions and of
+// every function refer to entries that exist: constants, builtins, locals,
+// free variables and jump targets. Global indices are two bytes wide and
+// always fit in the VM's globals store. How the instructions use the stack
+// is not checked.
+func checkReferences(main code.Instructions, constants []object.Object, builtins int) error {
+	// the instructions to check, with the index of the function constant
+	// they belong to, or -1 for the main instructions
+	type stream struct {
+		constant int
+		ins      code.Instructions
+		locals   int
+	}
+	streams := []stream{{-1, main, 0}}
+	for i, constant := range constants {
+		if fn, ok := constant.(*object.CompiledFunction); ok {
+			streams = append(streams, stream{i, fn.Instructions, fn.NumLocals})
+		}
+	}
+	wrap := func(s stream, err error) error {
+		if err == nil || s.constant < 0 {
+			return err
+		}
+		return fmt.Errorf("constant %d: %w", s.constant, err)
+	}
+
+	// the number of free variables a function has is only known from the
+	// OpClosure instructions that create closures of it
+	numFree := map[int]int{}
+	for _, s := range streams {
+		err := eachInstruction(s.ins, func(offset int, op code.Opcode, operands []int) error {
+			if op != code.OpClosure {
+				return nil
+			}
+			if operands[0] >= len(constants) {
+				return fmt.Errorf("offset %d: constant %d out of range", offset, operands[0])
+			}
+			if _, ok := constants[operands[0]].(*object.CompiledFunction); !ok {
+				return fmt.Errorf("offset %d: constant %d is not a function", offset, operands[0])
+			}
+			if n, ok := numFree[operands[0]]; ok && n != operands[1] {
+				return fmt.Errorf("offset %d: closures of constant %d have %d and %d free variables",
+					offset, operands[0], n, operands[1])
+			}
+			numFree[operands[0]] = operands[1]
+			return nil
+		})
+		if err != nil {
+			return wrap(s, err)
+		}
+	}
+
+	for _, s := range streams {
+		starts := map[int]bool{}
+		eachInstruction(s.ins, func(offset int, _ code.Opcode, _ []int) error {
+			starts[offset] = true
+			return nil
+		})
+
+		err := eachInstruction(s.ins, func(offset int, op code.Opcode, operands []int) error {
+			switch op {
+			case code.OpConstant:
+				if operands[0] >= len(constants) {
+					return fmt.Errorf("offset %d: constant %d out of range", offset, operands[0])
+				}
+			case code.OpGetBuiltin:
+				if operands[0] >= builtins {
+					return fmt.Errorf("offset %d: builtin %d out of range", offset, operands[0])
+				}
+			case code.OpGetLocal, code.OpSetLocal:
+				if operands[0] >= s.locals {
+					return fmt.Errorf("offset %d: local %d out of range", offset, operands[0])
+				}
+			case code.OpGetFree:
+				if operands[0] >= numFree[s.constant] {
+					return fmt.Errorf("offset %d: free variable %d out of range", offset, operands[0])
+				}
+			case code.OpJump, code.OpJumpNotTruthy, code.OpIterNext:
+				if operands[0] != len(s.ins) && !starts[operands[0]] {
+					return fmt.Errorf("offset %d: jump to %d is not an instruction", offset, operands[0])
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return wrap(s, err)
+		}
+	}
+
+	return nil
+}
+
+// eachInstruction calls f with each instruction of ins, which
+// checkInstructions has already found to be well formed, and stops at the
+// first error.
+func eachInstruction(ins code.Instructions, f func(offset int, op code.Opcode, operands []int) error) error {
+	for i := 0; i < len(ins); {
+		def, _ := code.Lookup(ins[i])
+		operands, read := code.ReadOperands(def, ins[i+1:])
+
+		if err := f(i, code.Opcode(ins[i]), operands); err != nil {
+			return err
+		}
+		i += 1 + read
+	}
+	return nil
+}
+
+func (d *vycDecoder) constant() object.Object {
+	tag := d.next(1)
+	if d.err != nil {
+		return nil
+	}
+
+	switch tag[0] {
+	case vycInteger:
+		return &object.Integer{Value: d.varint()}
+	case vycFloat:
+		bits := d.next(8)
+		if d.err != nil {
+			return nil
+		}
+		return &object.Float{Value: math.Float64frombits(binary.BigEndian.Uint64(bits))}
+	case vycString:
+		return &object.String{Value: d.string()}
+	case vycFunction:
+		fn := &object.CompiledFunction{Name: d.string()}
+		fn.NumLocals = d.uvarint()
+		fn.NumParameters = d.uvarint()
+		fn.Instructions, fn.Lines = d.instructions()
+		return fn
+	default:
+		d.err = fmt.Errorf("unknown constant tag %q", tag[0])
+		return nil
+	}
+}
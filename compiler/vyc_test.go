@@ -0,0 +1,141 @@
+package compiler
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"wavy/code"
+	"wavy/lexer"
+	"wavy/object"
+	"wavy/parser"
+)
+
+func compileForVyc(t *testing.T, input string) *Bytecode {
+	t.Helper()
+
+	p := parser.New(lexer.NewWithFile(input, "main.vy"))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+
+	compiler := New()
+	if err := compiler.Compile(program); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	return compiler.Bytecode()
+}
+
+func TestVycRoundTrip(t *testing.T) {
+	inputs := []string{
+		`1 + 2`,
+		`let x = -9000000000; let y = 2.5; "héllo" + "world"`,
+		`let add = fn(a, b) { let c = a + b; c };
+let adder = fn(a) { fn(b) { add(a, b) } };
+adder(1)(2)`,
+		`let xs = [1, 2, 3]; for (x in xs) { if (x == 2) { continue; } puts(x) }`,
+		``,
+	}
+
+	for _, input := range inputs {
+		bytecode := compileForVyc(t, input)
+
+		var buf bytes.Buffer
+		if _, err := bytecode.WriteTo(&buf); err != nil {
+			t.Fatalf("%q: could not write: %s", input, err)
+		}
+
+		if !IsVyc(buf.Bytes()) {
+			t.Fatalf("%q: output does not start with the magic number", input)
+		}
+
+		decoded, err := ReadBytecode(&buf)
+		if err != nil {
+			t.Fatalf("%q: could not read: %s", input, err)
+		}
+
+		if !reflect.DeepEqual(decoded, bytecode) {
+			t.Errorf("%q: round trip changed bytecode.\nwant=%+v\ngot =%+v",
+				input, bytecode, decoded)
+		}
+	}
+}
+
+// marshalInstructions writes raw instructions and constants with a valid
+// checksum, to check that the decoder does not trust them.
+func marshalInstructions(t *testing.T, ins []byte, constants ...object.Object) []byte {
+	t.Helper()
+
+	data, err := (&Bytecode{Instructions: ins, Constants: constants}).MarshalBinary()
+	if err != nil {
+		t.Fatalf("could not marshal: %s", err)
+	}
+	return data
+}
+
+func TestVycErrors(t *testing.T) {
+	valid, err := compileForVyc(t, `let f = fn(x) { x * 2.0 }; f("a")`).MarshalBinary()
+	if err != nil {
+		t.Fatalf("could not marshal: %s", err)
+	}
+
+	modify := func(f func(data []byte) []byte) []byte {
+		return f(append([]byte{}, valid...))
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"empty", []byte{}, "not a compiled wavy file"},
+		{"source", []byte("let x = 1;"), "not a compiled wavy file"},
+		{"short header", valid[:10], "truncated header"},
+		{"version", modify(func(d []byte) []byte { d[5] = 99; return d }), "unsupported version 99"},
+		{"builtins", modify(func(d []byte) []byte { d[6] = 0xFF; return d }), "builtins"},
+		{"truncated", valid[:len(valid)-3], "header says"},
+		{"corrupt", modify(func(d []byte) []byte { d[len(d)-1] ^= 0xFF; return d }), "checksum mismatch"},
+		{"bad opcode", marshalInstructions(t, []byte{0xFF}), "opcode 255 undefined"},
+		{"missing operand", marshalInstructions(t, []byte{byte(code.OpConstant), 0}), "missing operands"},
+		{"constant", marshalInstructions(t, code.Make(code.OpConstant, 500)), "offset 0: constant 500 out of range"},
+		{"builtin", marshalInstructions(t, code.Make(code.OpGetBuiltin, 255)), "builtin 255 out of range"},
+		{"local", marshalInstructions(t, code.Make(code.OpGetLocal, 0)), "local 0 out of range"},
+		{
+			"jump into an operand",
+			marshalInstructions(t, append(code.Make(code.OpJump, 4), code.Make(code.OpConstant, 0)...), &object.Integer{Value: 1}),
+			"offset 0: jump to 4 is not an instruction",
+		},
+		{"jump past the end", marshalInstructions(t, code.Make(code.OpJump, 9)), "jump to 9 is not an instruction"},
+		{
+			"closure of an integer",
+			marshalInstructions(t, code.Make(code.OpClosure, 0, 0), &object.Integer{Value: 1}),
+			"constant 0 is not a function",
+		},
+		{
+			"free variable",
+			marshalInstructions(t, code.Make(code.OpClosure, 0, 1),
+				&object.CompiledFunction{Instructions: code.Make(code.OpGetFree, 1)}),
+			"constant 0: offset 0: free variable 1 out of range",
+		},
+		{
+			"function local",
+			marshalInstructions(t, nil,
+				&object.CompiledFunction{Instructions: code.Make(code.OpSetLocal, 2), NumLocals: 2}),
+			"constant 0: offset 0: local 2 out of range",
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := UnmarshalBytecode(tt.data)
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%s: wrong error. want it to contain %q, got=%q",
+				tt.name, tt.expected, err)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"wavy/audio"
+)
+
+// Butterworth is the Q of a second-order Butterworth response, which is
+// maximally flat in the passband. It is the usual default for lowpass and
+// highpass filters.
+const Butterworth = math.Sqrt2 / 2
+
+// Biquad is a second-order IIR filter with coefficients normalized so that
+// a0 is 1:
+//
+//	y[n] = B0*x[n] + B1*x[n-1] + B2*x[n-2] - A1*y[n-1] - A2*y[n-2]
+//
+// The constructors design filters from Robert Bristow-Johnson's Audio EQ
+// Cookbook, for audio at sampleRate. freq is the cutoff or center frequency
+// in hertz and must be below the Nyquist frequency, and q must be positive.
+type Biquad struct {
+	B0, B1, B2 float64
+	A1, A2     float64
+}
+
+// LowPass passes frequencies below freq. It is 3 dB down at freq when q is
+// Butterworth.
+func LowPass(sampleRate, freq, q float64) (Biquad, error) {
+	w, err := newCookbook(sampleRate, freq, q, 0)
+	if err != nil {
+		return Biquad{}, err
+	}
+
+	return w.normalize(
+		(1-w.cos)/2, 1-w.cos, (1-w.cos)/2,
+		1+w.alpha, -2*w.cos, 1-w.alpha,
+	), nil
+}
+
+// HighPass passes frequencies above freq.
+func HighPass(sampleRate, freq, q float64) (Biquad, error) {
+	w, err := newCookbook(sampleRate, freq, q, 0)
+	if err != nil {
+		return Biquad{}, err
+	}
+
+	return w.normalize(
+		(1+w.cos)/2, -(1 + w.cos), (1+w.cos)/2,
+		1+w.alpha, -2*w.cos, 1-w.alpha,
+	), nil
+}
+
+// BandPass passes a band around freq with a gain of 0 dB at its center. A
+// higher q makes the band narrower.
+func BandPass(sampleRate, freq, q float64) (Biquad, error) {
+	w, err := newCookbook(sampleRate, freq, q, 0)
+	if err != nil {
+		return Biquad{}, err
+	}
+
+	return w.normalize(
+		w.alpha, 0, -w.alpha,
+		1+w.alpha, -2*w.cos, 1-w.alpha,
+	), nil
+}
+
+// Notch removes a band around freq. A higher q makes the band narrower.
+func Notch(sampleRate, freq, q float64) (Biquad, error) {
+	w, err := newCookbook(sampleRate, freq, q, 0)
+	if err != nil {
+		return Biquad{}, err
+	}
+
+	return w.normalize(
+		1, -2*w.cos, 1,
+		1+w.alpha, -2*w.cos, 1-w.alpha,
+	), nil
+}
+
+// Peaking boosts or cuts a band around freq by gainDB decibels.
+func Peaking(sampleRate, freq, q, gainDB float64) (Biquad, error) {
+	w, err := newCookbook(sampleRate, freq, q, gainDB)
+	if err != nil {
+		return Biquad{}, err
+	}
+
+	return w.normalize(
+		1+w.alpha*w.a, -2*w.cos, 1-w.alpha*w.a,
+		1+w.alpha/w.a, -2*w.cos, 1-w.alpha/w.a,
+	), nil
+}
+
+// LowShelf boosts or cuts frequencies below freq by gainDB decibels. freq
+// is the midpoint of the transition, where the gain is half of gainDB.
+func LowShelf(sampleRate, freq, q, gainDB float64) (Biquad, error) {
+	w, err := newCookbook(sampleRate, freq, q, gainDB)
+	if err != nil {
+		return Biquad{}, err
+	}
+
+	a := w.a
+	sq := 2 * math.Sqrt(a) * w.alpha
+
+	return w.normalize(
+		a*((a+1)-(a-1)*w.cos+sq), 2*a*((a-1)-(a+1)*w.cos), a*((a+1)-(a-1)*w.cos-sq),
+		(a+1)+(a-1)*w.cos+sq, -2*((a-1)+(a+1)*w.cos), (a+1)+(a-1)*w.cos-sq,
+	), nil
+}
+
+// HighShelf boosts or cuts frequencies above freq by gainDB decibels.
+func HighShelf(sampleRate, freq, q, gainDB float64) (Biquad, error) {
+	w, err := newCookbook(sampleRate, freq, q, gainDB)
+	if err != nil {
+		return Biquad{}, err
+	}
+
+	a := w.a
+	sq := 2 * math.Sqrt(a) * w.alpha
+
+	return w.normalize(
+		a*((a+1)+(a-1)*w.cos+sq), -2*a*((a-1)+(a+1)*w.cos), a*((a+1)+(a-1)*w.cos-sq),
+		(a+1)-(a-1)*w.cos+sq, 2*((a-1)-(a+1)*w.cos), (a+1)-(a-1)*w.cos-sq,
+	), nil
+}
+
+// cookbook holds the intermediate values shared by the cookbook formulas.
+type cookbook struct {
+	cos, alpha float64
+	a          float64 // square root of the linear gain, for peaking and shelves
+}
+
+func newCookbook(sampleRate, freq, q, gainDB float64) (cookbook, error) {
+	if sampleRate <= 0 {
+		return cookbook{}, fmt.Errorf("sample rate must be positive, got %g", sampleRate)
+	}
+	if !(freq > 0 && freq < sampleRate/2) {
+		return cookbook{}, fmt.Errorf("filter frequency must be between 0 and %g Hz, got %g",
+			sampleRate/2, freq)
+	}
+	if !(q > 0) {
+		return cookbook{}, fmt.Errorf("filter Q must be positive, got %g", q)
+	}
+
+	w0 := 2 * math.Pi * freq / sampleRate
+	return cookbook{
+		cos:   math.Cos(w0),
+		alpha: math.Sin(w0) / (2 * q),
+		a:     math.Pow(10, gainDB/40),
+	}, nil
+}
+
+func (c cookbook) normalize(b0, b1, b2, a0, a1, a2 float64) Biquad {
+	return Biquad{B0: b0 / a0, B1: b1 / a0, B2: b2 / a0, A1: a1 / a0, A2: a2 / a0}
+}
+
+// Response returns the gain of the filter at freq for audio at sampleRate,
+// as a linear factor.
+func (f Biquad) Response(sampleRate, freq float64) float64 {
+	z := cmplx.Exp(complex(0, -2*math.Pi*freq/sampleRate)) // z^-1
+	num := complex(f.B0, 0) + complex(f.B1, 0)*z + complex(f.B2, 0)*z*z
+	den := 1 + complex(f.A1, 0)*z + complex(f.A2, 0)*z*z
+	return cmplx.Abs(num / den)
+}
+
+// Process filters each channel of b separately. The filter starts at rest
+// and its state carries over from one frame to the next for the whole
+// buffer, so a buffer filtered in one call has no discontinuities.
+func (f Biquad) Process(b *audio.Buffer) *audio.Buffer {
+	samples := make([]float32, len(b.Samples))
+
+	// transposed direct form II, one pair of state variables per channel
+	s1 := make([]float64, b.Channels)
+	s2 := make([]float64, b.Channels)
+
+	for i, s := range b.Samples {
+		c := i % b.Channels
+		x := float64(s)
+
+		y := f.B0*x + s1[c]
+		s1[c] = f.B1*x - f.A1*y + s2[c]
+		s2[c] = f.B2*x - f.A2*y
+
+		samples[i] = float32(y)
+	}
+
+	return withSamples(b, samples)
+}
@@ -0,0 +1,164 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+	"wavy/audio"
+)
+
+const testRate = 48000.0
+
+func TestBiquadResponse(t *testing.T) {
+	lowpass, _ := LowPass(testRate, 1000, Butterworth)
+	resonant, _ := LowPass(testRate, 1000, 2)
+	highpass, _ := HighPass(testRate, 1000, Butterworth)
+	bandpass, _ := BandPass(testRate, 2000, 4)
+	notch, _ := Notch(testRate, 2000, 4)
+	peaking, _ := Peaking(testRate, 3000, 1, 6)
+	lowShelf, _ := LowShelf(testRate, 200, Butterworth, -12)
+	highShelf, _ := HighShelf(testRate, 8000, Butterworth, 9)
+
+	nyquist := testRate / 2
+
+	tests := []struct {
+		name       string
+		filter     Biquad
+		freq       float64
+		expectedDB float64
+	}{
+		// the cookbook filters hit these values exactly, since the bilinear
+		// transform is prewarped to map the analog prototype's center
+		// frequency onto freq
+		{"lowpass DC", lowpass, 0, 0},
+		{"lowpass cutoff", lowpass, 1000, GainToDB(Butterworth)},
+		{"lowpass nyquist", lowpass, nyquist, math.Inf(-1)},
+		{"resonant lowpass cutoff", resonant, 1000, GainToDB(2)},
+		{"highpass DC", highpass, 0, math.Inf(-1)},
+		{"highpass cutoff", highpass, 1000, GainToDB(Butterworth)},
+		{"highpass nyquist", highpass, nyquist, 0},
+		{"bandpass DC", bandpass, 0, math.Inf(-1)},
+		{"bandpass center", bandpass, 2000, 0},
+		{"bandpass nyquist", bandpass, nyquist, math.Inf(-1)},
+		{"notch DC", notch, 0, 0},
+		{"notch center", notch, 2000, math.Inf(-1)},
+		{"notch nyquist", notch, nyquist, 0},
+		{"peaking DC", peaking, 0, 0},
+		{"peaking center", peaking, 3000, 6},
+		{"peaking nyquist", peaking, nyquist, 0},
+		{"low shelf DC", lowShelf, 0, -12},
+		{"low shelf midpoint", lowShelf, 200, -6},
+		{"low shelf nyquist", lowShelf, nyquist, 0},
+		{"high shelf DC", highShelf, 0, 0},
+		{"high shelf midpoint", highShelf, 8000, 4.5},
+		{"high shelf nyquist", highShelf, nyquist, 9},
+	}
+
+	for _, tt := range tests {
+		gain := tt.filter.Response(testRate, tt.freq)
+
+		if math.IsInf(tt.expectedDB, -1) {
+			if gain > 1e-6 {
+				t.Errorf("%s: not a zero of the filter. gain=%g", tt.name, gain)
+			}
+			continue
+		}
+
+		if db := GainToDB(gain); math.Abs(db-tt.expectedDB) > 1e-6 {
+			t.Errorf("%s: wrong response. want=%.4f dB, got=%.4f dB", tt.name, tt.expectedDB, db)
+		}
+	}
+}
+
+func TestBiquadProcessMatchesResponse(t *testing.T) {
+	lowpass, _ := LowPass(testRate, 1000, Butterworth)
+	highpass, _ := HighPass(testRate, 500, 0.5)
+	bandpass, _ := BandPass(testRate, 2000, 4)
+	peaking, _ := Peaking(testRate, 3000, 2, -10)
+	highShelf, _ := HighShelf(testRate, 8000, 1, 6)
+
+	tests := []struct {
+		name   string
+		filter Biquad
+		freq   float64
+	}{
+		{"lowpass passband", lowpass, 100},
+		{"lowpass cutoff", lowpass, 1000},
+		{"lowpass stopband", lowpass, 8000},
+		{"highpass stopband", highpass, 50},
+		{"highpass passband", highpass, 5000},
+		{"bandpass center", bandpass, 2000},
+		{"bandpass skirt", bandpass, 3000},
+		{"peaking center", peaking, 3000},
+		{"high shelf", highShelf, 15000},
+	}
+
+	for _, tt := range tests {
+		in := sineBuffer(tt.freq, int(testRate), int(testRate)/2, 1)
+		out := tt.filter.Process(in)
+
+		// compare levels once the filter has settled
+		half := len(out.Samples) / 2
+		got := rmsOf(out.Samples[half:]) / rmsOf(in.Samples[half:])
+		want := tt.filter.Response(testRate, tt.freq)
+
+		if math.Abs(GainToDB(got)-GainToDB(want)) > 0.01 {
+			t.Errorf("%s: processed gain %.3f dB, response %.3f dB", tt.name,
+				GainToDB(got), GainToDB(want))
+		}
+	}
+}
+
+func TestBiquadChannelsAreIndependent(t *testing.T) {
+	filter, _ := LowPass(testRate, 1000, Butterworth)
+
+	mono := sineBuffer(300, int(testRate), 4800, 1)
+	stereo := &audio.Buffer{
+		Samples:    make([]float32, 2*len(mono.Samples)),
+		SampleRate: mono.SampleRate,
+		Channels:   2,
+	}
+	for i, s := range mono.Samples {
+		stereo.Samples[2*i] = s
+	}
+
+	want := filter.Process(mono)
+	got := filter.Process(stereo)
+
+	for i := range want.Samples {
+		if got.Samples[2*i] != want.Samples[i] || got.Samples[2*i+1] != 0 {
+			t.Fatalf("frame %d: want=[%g 0], got=[%g %g]", i, want.Samples[i],
+				got.Samples[2*i], got.Samples[2*i+1])
+		}
+	}
+}
+
+func TestBiquadErrors(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{filterError(LowPass(testRate, 0, 1)), "filter frequency must be between 0 and 24000 Hz, got 0"},
+		{filterError(HighPass(testRate, 24000, 1)), "filter frequency must be between 0 and 24000 Hz, got 24000"},
+		{filterError(BandPass(testRate, 1000, 0)), "filter Q must be positive, got 0"},
+		{filterError(Notch(testRate, 1000, math.NaN())), "filter Q must be positive, got NaN"},
+		{filterError(Peaking(0, 1000, 1, 3)), "sample rate must be positive, got 0"},
+	}
+
+	for _, tt := range tests {
+		if tt.err == nil || tt.err.Error() != tt.expected {
+			t.Errorf("wrong error. want=%q, got=%v", tt.expected, tt.err)
+		}
+	}
+}
+
+func rmsOf(samples []float32) float64 {
+	sum := 0.0
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+func filterError(_ Biquad, err error) error {
+	return err
+}
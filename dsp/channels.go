@@ -0,0 +1,160 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+	"wavy/audio"
+)
+
+// ToMono mixes the channels of b down to one by averaging them.
+func ToMono(b *audio.Buffer) *audio.Buffer {
+	frames := b.Frames()
+	samples := make([]float32, frames)
+
+	for i := 0; i < frames; i++ {
+		sum := 0.0
+		for _, s := range b.Samples[i*b.Channels : (i+1)*b.Channels] {
+			sum += float64(s)
+		}
+		samples[i] = float32(sum / float64(b.Channels))
+	}
+
+	return &audio.Buffer{
+		Samples:    samples,
+		SampleRate: b.SampleRate,
+		Channels:   1,
+		BitDepth:   b.BitDepth,
+	}
+}
+
+// Pan places b in the stereo field: -1 is hard left, 0 the center and 1
+// hard right.
+//
+// Mono audio is panned with a constant-power law, so it keeps the same
+// loudness as it moves and is 3 dB down in each channel at the center. Stereo
+// audio is balanced instead: the channel it moves away from is attenuated
+// and the other is left alone, so position 0 returns it unchanged.
+func Pan(b *audio.Buffer, position float64) (*audio.Buffer, error) {
+	if position < -1 || position > 1 || math.IsNaN(position) {
+		return nil, fmt.Errorf("pan position must be between -1 and 1, got %g", position)
+	}
+
+	var left, right float64
+	switch b.Channels {
+	case 1:
+		angle := (position + 1) * math.Pi / 4
+		left, right = math.Cos(angle), math.Sin(angle)
+	case 2:
+		left, right = math.Min(1, 1-position), math.Min(1, 1+position)
+	default:
+		return nil, fmt.Errorf("cannot pan %d-channel audio", b.Channels)
+	}
+
+	frames := b.Frames()
+	samples := make([]float32, 2*frames)
+	for i := 0; i < frames; i++ {
+		l, r := b.Samples[i*b.Channels], b.Samples[(i+1)*b.Channels-1]
+		samples[2*i] = float32(float64(l) * left)
+		samples[2*i+1] = float32(float64(r) * right)
+	}
+
+	return &audio.Buffer{
+		Samples:    samples,
+		SampleRate: b.SampleRate,
+		Channels:   2,
+		BitDepth:   b.BitDepth,
+	}, nil
+}
+
+// SplitChannels returns each channel of b as a mono buffer.
+func SplitChannels(b *audio.Buffer) []*audio.Buffer {
+	frames := b.Frames()
+	channels := make([]*audio.Buffer, b.Channels)
+
+	for c := range channels {
+		samples := make([]float32, frames)
+		for i := range samples {
+			samples[i] = b.Samples[i*b.Channels+c]
+		}
+
+		channels[c] = &audio.Buffer{
+			Samples:    samples,
+			SampleRate: b.SampleRate,
+			Channels:   1,
+			BitDepth:   b.BitDepth,
+		}
+	}
+
+	return channels
+}
+
+// MergeChannels interleaves buffers into one buffer whose channels are
+// those of each buffer in turn, so merging the result of SplitChannels
+// gives back the original. The buffers must have the same sample rate;
+// shorter ones are padded with silence, and the result has the largest bit
+// depth among them.
+func MergeChannels(buffers []*audio.Buffer) (*audio.Buffer, error) {
+	if len(buffers) == 0 {
+		return nil, fmt.Errorf("nothing to merge")
+	}
+
+	out := &audio.Buffer{SampleRate: buffers[0].SampleRate}
+	frames := 0
+	for _, b := range buffers {
+		if b.SampleRate != out.SampleRate {
+			return nil, fmt.Errorf("mismatched sample rates: %dHz and %dHz", out.SampleRate, b.SampleRate)
+		}
+		out.Channels += b.Channels
+		out.BitDepth = max(out.BitDepth, b.BitDepth)
+		frames = max(frames, b.Frames())
+	}
+
+	out.Samples = make([]float32, frames*out.Channels)
+	first := 0
+	for _, b := range buffers {
+		for i, s := range b.Samples {
+			n, c := i/b.Channels, i%b.Channels
+			out.Samples[n*out.Channels+first+c] = s
+		}
+		first += b.Channels
+	}
+
+	return out, nil
+}
+
+// Remix mixes the channels of b into new ones: output channel i is the sum
+// of each input channel j scaled by matrix[i][j]. Each row of matrix must
+// have one gain per channel of b.
+func Remix(b *audio.Buffer, matrix [][]float64) (*audio.Buffer, error) {
+	if len(matrix) == 0 {
+		return nil, fmt.Errorf("remix matrix must have at least one row")
+	}
+	for i, row := range matrix {
+		if len(row) != b.Channels {
+			return nil, fmt.Errorf("row %d of the remix matrix has %d gains for %d-channel audio",
+				i+1, len(row), b.Channels)
+		}
+	}
+
+	frames := b.Frames()
+	channels := len(matrix)
+	samples := make([]float32, frames*channels)
+
+	for n := 0; n < frames; n++ {
+		in := b.Samples[n*b.Channels : (n+1)*b.Channels]
+		for i, row := range matrix {
+			sum := 0.0
+			for j, gain := range row {
+				sum += gain * float64(in[j])
+			}
+			samples[n*channels+i] = float32(sum)
+		}
+	}
+
+	return &audio.Buffer{
+		Samples:    samples,
+		SampleRate: b.SampleRate,
+		Channels:   channels,
+		BitDepth:   b.BitDepth,
+	}, nil
+}
@@ -0,0 +1,123 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+	"wavy/audio"
+)
+
+func TestToMono(t *testing.T) {
+	stereo := &audio.Buffer{
+		Samples:    []float32{0.5, -0.5, 0.25, 0.75, 1, 0},
+		SampleRate: 8000, Channels: 2, BitDepth: 16,
+	}
+
+	mono := ToMono(stereo)
+	if mono.Channels != 1 || mono.SampleRate != 8000 || mono.BitDepth != 16 {
+		t.Errorf("wrong format. got=%dHz %dch %d-bit", mono.SampleRate, mono.Channels, mono.BitDepth)
+	}
+	testSamples(t, "to mono", []float32{0, 0.5, 0.5}, mono.Samples)
+
+	again := ToMono(mono)
+	testSamples(t, "to mono twice", mono.Samples, again.Samples)
+}
+
+func TestPan(t *testing.T) {
+	mono := &audio.Buffer{Samples: []float32{1, 0.5}, SampleRate: 8000, Channels: 1}
+	stereo := &audio.Buffer{Samples: []float32{1, 0.5}, SampleRate: 8000, Channels: 2}
+	center := float32(math.Sqrt2 / 2)
+
+	tests := []struct {
+		input    *audio.Buffer
+		position float64
+		expected []float32
+	}{
+		{mono, -1, []float32{1, 0, 0.5, 0}},
+		{mono, 0, []float32{center, center, center / 2, center / 2}},
+		{mono, 1, []float32{0, 1, 0, 0.5}},
+		{stereo, 0, []float32{1, 0.5}},
+		{stereo, -0.5, []float32{1, 0.25}},
+		{stereo, 1, []float32{0, 0.5}},
+	}
+
+	for _, tt := range tests {
+		panned := must(Pan(tt.input, tt.position))
+		if panned.Channels != 2 {
+			t.Errorf("pan(%dch, %g): not stereo. got=%dch", tt.input.Channels, tt.position, panned.Channels)
+		}
+		testSamples(t, "pan", tt.expected, panned.Samples)
+	}
+}
+
+func TestSplitAndMergeChannels(t *testing.T) {
+	stereo := &audio.Buffer{
+		Samples:    []float32{0.5, -0.5, 0.25, 0.75, 1, 0},
+		SampleRate: 8000, Channels: 2, BitDepth: 24,
+	}
+
+	channels := SplitChannels(stereo)
+	if len(channels) != 2 {
+		t.Fatalf("wrong number of channels. want=2, got=%d", len(channels))
+	}
+	for _, c := range channels {
+		if c.Channels != 1 || c.SampleRate != 8000 || c.BitDepth != 24 {
+			t.Errorf("wrong format. got=%dHz %dch %d-bit", c.SampleRate, c.Channels, c.BitDepth)
+		}
+	}
+	testSamples(t, "left", []float32{0.5, 0.25, 1}, channels[0].Samples)
+	testSamples(t, "right", []float32{-0.5, 0.75, 0}, channels[1].Samples)
+
+	merged := must(MergeChannels(channels))
+	if merged.Channels != 2 || merged.BitDepth != 24 {
+		t.Errorf("wrong merged format. got=%dch %d-bit", merged.Channels, merged.BitDepth)
+	}
+	testSamples(t, "merge", stereo.Samples, merged.Samples)
+
+	// channels are swapped by merging in a different order, shorter inputs
+	// are padded and stereo inputs contribute both channels
+	short := &audio.Buffer{Samples: []float32{0.1}, SampleRate: 8000, Channels: 1, BitDepth: 16}
+	three := must(MergeChannels([]*audio.Buffer{short, stereo}))
+	if three.Channels != 3 || three.BitDepth != 24 {
+		t.Errorf("wrong merged format. got=%dch %d-bit", three.Channels, three.BitDepth)
+	}
+	testSamples(t, "merge padded", []float32{0.1, 0.5, -0.5, 0, 0.25, 0.75, 0, 1, 0}, three.Samples)
+}
+
+func TestRemix(t *testing.T) {
+	// L, R, C, LFE, Ls, Rs
+	surround := &audio.Buffer{
+		Samples:    []float32{0.1, 0.2, 0.4, 1, 0.3, -0.3},
+		SampleRate: 48000, Channels: 6, BitDepth: 24,
+	}
+	const g = math.Sqrt2 / 2
+	downmix := [][]float64{
+		{1, 0, g, 0, g, 0},
+		{0, 1, g, 0, 0, g},
+	}
+
+	stereo := must(Remix(surround, downmix))
+	if stereo.Channels != 2 || stereo.SampleRate != 48000 || stereo.BitDepth != 24 {
+		t.Errorf("wrong format. got=%dHz %dch %d-bit", stereo.SampleRate, stereo.Channels, stereo.BitDepth)
+	}
+	testSamples(t, "5.1 to stereo", []float32{float32(0.1 + 0.7*g), float32(0.2 + 0.1*g)}, stereo.Samples)
+
+	mono := &audio.Buffer{Samples: []float32{0.5, 1}, SampleRate: 8000, Channels: 1}
+	testSamples(t, "mono to stereo", []float32{0.5, -0.25, 1, -0.5},
+		must(Remix(mono, [][]float64{{1}, {-0.5}})).Samples)
+
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{errorOf(Remix(mono, nil)), "remix matrix must have at least one row"},
+		{errorOf(Remix(surround, [][]float64{{1, 1}})), "row 1 of the remix matrix has 2 gains for 6-channel audio"},
+		{errorOf(MergeChannels(nil)), "nothing to merge"},
+		{errorOf(MergeChannels([]*audio.Buffer{mono, surround})), "mismatched sample rates: 8000Hz and 48000Hz"},
+	}
+
+	for _, tt := range tests {
+		if tt.err == nil || tt.err.Error() != tt.expected {
+			t.Errorf("wrong error. want=%q, got=%v", tt.expected, tt.err)
+		}
+	}
+}
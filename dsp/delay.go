@@ -0,0 +1,177 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+	"wavy/audio"
+)
+
+// The time-based effects keep the length of their input, so an echo or
+// reverb tail that runs past the end is cut off. Append silence to the
+// input to hear it. All of them are deterministic: the same input and
+// parameters always give the same output.
+
+// Delay mixes b with a copy of itself delayed by secs seconds. The delayed
+// signal is fed back into the delay line scaled by feedback, so each repeat
+// is quieter than the last, and mix sets the balance between the dry input
+// (0) and the delayed signal (1).
+func Delay(b *audio.Buffer, secs, feedback, mix float64) (*audio.Buffer, error) {
+	if err := checkDelay(b, secs); err != nil {
+		return nil, err
+	}
+	if err := checkFeedback(feedback); err != nil {
+		return nil, err
+	}
+	if err := checkMix(mix); err != nil {
+		return nil, err
+	}
+
+	d := delayFrames(b, secs)
+	ch := b.Channels
+	line := make([]float64, len(b.Samples))
+	samples := make([]float32, len(b.Samples))
+
+	for i, s := range b.Samples {
+		x := float64(s)
+
+		delayed := 0.0
+		if i >= d*ch {
+			delayed = line[i-d*ch]
+		}
+
+		line[i] = x + feedback*delayed
+		samples[i] = float32((1-mix)*x + mix*delayed)
+	}
+
+	return withSamples(b, samples), nil
+}
+
+// A Tap is one repeat of a multi-tap echo.
+type Tap struct {
+	Delay float64 // seconds after the input
+	Gain  float64 // linear gain of the repeat
+}
+
+// Echo adds a repeat of b for each tap. There is no feedback, so each tap
+// is heard once.
+func Echo(b *audio.Buffer, taps []Tap) (*audio.Buffer, error) {
+	offsets := make([]int, len(taps))
+	for i, tap := range taps {
+		if err := checkDelay(b, tap.Delay); err != nil {
+			return nil, err
+		}
+		offsets[i] = delayFrames(b, tap.Delay) * b.Channels
+	}
+
+	samples := make([]float32, len(b.Samples))
+	for i, s := range b.Samples {
+		y := float64(s)
+		for t, tap := range taps {
+			if j := i - offsets[t]; j >= 0 {
+				y += tap.Gain * float64(b.Samples[j])
+			}
+		}
+		samples[i] = float32(y)
+	}
+
+	return withSamples(b, samples), nil
+}
+
+// Chorus mixes b with copies delayed by around 20 ms, where the delay is
+// swept by depth seconds at rate hertz by a sine LFO. Each channel's LFO is
+// a quarter cycle behind the previous one's, which widens stereo audio.
+func Chorus(b *audio.Buffer, rate, depth, mix float64) (*audio.Buffer, error) {
+	return modulatedDelay(b, 0.02, rate, depth, 0, mix)
+}
+
+// Flanger is like Chorus with a delay of a few milliseconds and feedback,
+// which gives a sweeping comb filter.
+func Flanger(b *audio.Buffer, rate, depth, feedback, mix float64) (*audio.Buffer, error) {
+	if err := checkFeedback(feedback); err != nil {
+		return nil, err
+	}
+	return modulatedDelay(b, 0.001, rate, depth, feedback, mix)
+}
+
+// modulatedDelay delays each channel by base + depth*(1 + sin)/2 seconds
+// and interpolates linearly between samples of the delay line.
+func modulatedDelay(b *audio.Buffer, base, rate, depth, feedback, mix float64) (*audio.Buffer, error) {
+	if !(rate >= 0) {
+		return nil, fmt.Errorf("LFO rate must not be negative, got %g", rate)
+	}
+	if !(depth >= 0 && depth <= 0.05) {
+		return nil, fmt.Errorf("modulation depth must be between 0 and 0.05 seconds, got %g", depth)
+	}
+	if err := checkMix(mix); err != nil {
+		return nil, err
+	}
+	if b.SampleRate <= 0 {
+		return nil, fmt.Errorf("cannot delay audio with sample rate %d", b.SampleRate)
+	}
+
+	sr := float64(b.SampleRate)
+	ch := b.Channels
+	frames := b.Frames()
+	line := make([]float64, len(b.Samples))
+	samples := make([]float32, len(b.Samples))
+
+	for c := 0; c < ch; c++ {
+		offset := float64(c) * math.Pi / 2
+
+		for n := 0; n < frames; n++ {
+			i := n*ch + c
+			x := float64(b.Samples[i])
+
+			lfo := (1 + math.Sin(2*math.Pi*rate*float64(n)/sr+offset)) / 2
+			pos := float64(n) - (base+depth*lfo)*sr
+
+			delayed := 0.0
+			if pos >= 0 {
+				k := int(pos)
+				frac := pos - float64(k)
+				delayed = line[k*ch+c] * (1 - frac)
+				if k+1 < n {
+					delayed += line[(k+1)*ch+c] * frac
+				}
+			}
+
+			line[i] = x + feedback*delayed
+			samples[i] = float32((1-mix)*x + mix*delayed)
+		}
+	}
+
+	return withSamples(b, samples), nil
+}
+
+// delayFrames returns the delay in frames, at most the length of b, since a
+// longer delay is never heard.
+func delayFrames(b *audio.Buffer, secs float64) int {
+	return int(math.Min(math.Round(secs*float64(b.SampleRate)), float64(b.Frames())))
+}
+
+func checkDelay(b *audio.Buffer, secs float64) error {
+	if !(secs > 0) {
+		return fmt.Errorf("delay must be positive, got %g", secs)
+	}
+	if math.IsInf(secs, 1) {
+		return fmt.Errorf("delay must be finite, got %g", secs)
+	}
+	if math.Round(secs*float64(b.SampleRate)) < 1 {
+		return fmt.Errorf("delay of %g seconds is shorter than one frame", secs)
+	}
+	return nil
+}
+
+func checkFeedback(feedback float64) error {
+	if !(feedback >= 0 && feedback < 1) {
+		return fmt.Errorf("feedback must be at least 0 and less than 1, got %g", feedback)
+	}
+	return nil
+}
+
+func checkMix(mix float64) error {
+	if !(mix >= 0 && mix <= 1) {
+		return fmt.Errorf("mix must be between 0 and 1, got %g", mix)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+	"wavy/audio"
+)
+
+func TestDelays(t *testing.T) {
+	impulse := &audio.Buffer{
+		Samples:    []float32{1, 0, 0, 0, 0, 0, 0, 0},
+		SampleRate: 4, Channels: 1, BitDepth: 16,
+	}
+	stereo := &audio.Buffer{
+		Samples:    []float32{1, -1, 0, 0, 0, 0, 0, 0},
+		SampleRate: 4, Channels: 2, BitDepth: 16,
+	}
+
+	tests := []struct {
+		name     string
+		result   *audio.Buffer
+		expected []float32
+	}{
+		{"delay", must(Delay(impulse, 0.5, 0.5, 0.5)), []float32{0.5, 0, 0.5, 0, 0.25, 0, 0.125, 0}},
+		{"delay wet", must(Delay(impulse, 0.75, 0, 1)), []float32{0, 0, 0, 1, 0, 0, 0, 0}},
+		{"delay stereo", must(Delay(stereo, 0.25, 0.5, 1)), []float32{0, 0, 1, -1, 0.5, -0.5, 0.25, -0.25}},
+		{"echo", must(Echo(impulse, []Tap{{0.25, 0.5}, {1, -0.25}})), []float32{1, 0.5, 0, 0, -0.25, 0, 0, 0}},
+		{"echo stereo", must(Echo(stereo, []Tap{{0.5, 0.5}})), []float32{1, -1, 0, 0, 0.5, -0.5, 0, 0}},
+		{"echo without taps", must(Echo(impulse, nil)), impulse.Samples},
+	}
+
+	for _, tt := range tests {
+		testSamples(t, tt.name, tt.expected, tt.result.Samples)
+	}
+
+	testSamples(t, "input", []float32{1, 0, 0, 0, 0, 0, 0, 0}, impulse.Samples)
+}
+
+func TestModulatedDelays(t *testing.T) {
+	in := sineBuffer(440, 44100, 44100, 2)
+
+	// without modulation the chorus is a plain delay of 20 ms
+	still := must(Chorus(in, 0, 0, 1))
+	testSamples(t, "chorus without modulation", in.Samples[:2*(44100-882)], still.Samples[2*882:])
+
+	chorus := must(Chorus(in, 1.5, 0.003, 0.5))
+	again := must(Chorus(in, 1.5, 0.003, 0.5))
+	testSamples(t, "chorus is deterministic", chorus.Samples, again.Samples)
+
+	if l, r := chorus.Samples[2*10000], chorus.Samples[2*10000+1]; l == r {
+		t.Errorf("chorus channels should have different LFO phases, both are %g", l)
+	}
+
+	flanger := must(Flanger(in, 0.25, 0.002, 0.9, 0.5))
+	if peak := Peak(flanger); peak > 5 || math.IsNaN(peak) {
+		t.Errorf("flanger feedback is unstable, peak=%g", peak)
+	}
+}
+
+func TestDelayErrors(t *testing.T) {
+	b := &audio.Buffer{Samples: []float32{0}, SampleRate: 8000, Channels: 1}
+
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{errorOf(Delay(b, 0, 0.5, 0.5)), "delay must be positive, got 0"},
+		{errorOf(Delay(b, 0.00001, 0.5, 0.5)), "delay of 1e-05 seconds is shorter than one frame"},
+		{errorOf(Delay(b, 0.1, 1, 0.5)), "feedback must be at least 0 and less than 1, got 1"},
+		{errorOf(Delay(b, 0.1, 0.5, 2)), "mix must be between 0 and 1, got 2"},
+		{errorOf(Echo(b, []Tap{{0.1, 1}, {-1, 1}})), "delay must be positive, got -1"},
+		{errorOf(Delay(b, math.Inf(1), 0.5, 0.5)), "delay must be finite, got +Inf"},
+		{errorOf(Echo(b, []Tap{{math.Inf(1), 1}})), "delay must be finite, got +Inf"},
+		{errorOf(Chorus(b, -1, 0.003, 0.5)), "LFO rate must not be negative, got -1"},
+		{errorOf(Chorus(b, 1, 0.1, 0.5)), "modulation depth must be between 0 and 0.05 seconds, got 0.1"},
+		{errorOf(Flanger(b, 1, 0.002, -0.5, 0.5)), "feedback must be at least 0 and less than 1, got -0.5"},
+	}
+
+	for _, tt := range tests {
+		if tt.err == nil || tt.err.Error() != tt.expected {
+			t.Errorf("wrong error. want=%q, got=%v", tt.expected, tt.err)
+		}
+	}
+
+	// a delay longer than the buffer is never heard, however long it is
+	stereo := &audio.Buffer{Samples: []float32{0.5, -0.5, 0.25, -0.25}, SampleRate: 8000, Channels: 2}
+	testSamples(t, "long delay", []float32{0.25, -0.25, 0.125, -0.125}, must(Delay(stereo, 1e15, 0.5, 0.5)).Samples)
+	testSamples(t, "long echo", stereo.Samples, must(Echo(stereo, []Tap{{1e15, 1}})).Samples)
+}
@@ -0,0 +1,50 @@
+// Package dsp implements audio effects and analysis on audio.Buffer values.
+// The builtins in package object call into it, but it has no dependencies on
+// the rest of wavy so that Go programs can use it directly.
+//
+// Effects never modify their input; they return a new buffer with the same
+// format unless they say otherwise. Samples are not clipped.
+package dsp
+
+import (
+	"math"
+	"wavy/audio"
+)
+
+// DBToGain converts a level in decibels to a linear gain factor.
+func DBToGain(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+// GainToDB converts a linear gain factor to decibels. A gain of zero is
+// negative infinity.
+func GainToDB(gain float64) float64 {
+	return 20 * math.Log10(gain)
+}
+
+// withSamples returns a buffer with the format of b and the given samples.
+func withSamples(b *audio.Buffer, samples []float32) *audio.Buffer {
+	return &audio.Buffer{
+		Samples:    samples,
+		SampleRate: b.SampleRate,
+		Channels:   b.Channels,
+		BitDepth:   b.BitDepth,
+	}
+}
+
+// scale returns a copy of b with every sample multiplied by gain.
+func scale(b *audio.Buffer, gain float64) *audio.Buffer {
+	samples := make([]float32, len(b.Samples))
+	for i, s := range b.Samples {
+		samples[i] = float32(float64(s) * gain)
+	}
+
+	return withSamples(b, samples)
+}
+
+// seconds converts a duration to a number of frames at the rate of b,
+// rounding to the nearest frame and limiting it to the length of b.
+func seconds(b *audio.Buffer, secs float64) int {
+	n := math.Round(secs * float64(b.SampleRate))
+	return int(math.Max(0, math.Min(float64(b.Frames()), n)))
+}
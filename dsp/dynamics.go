@@ -0,0 +1,244 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+	"wavy/audio"
+)
+
+// The dynamics processors measure the level of each frame as the largest
+// absolute sample over all channels and apply the same gain to every
+// channel, so the stereo image does not shift. Besides the processed
+// audio they return the gain reduction they applied to each frame, in
+// decibels: 0 where the audio was left alone and positive where it was
+// turned down.
+
+// Compressor is a feed-forward compressor, which turns down audio above
+// ThresholdDB so that each decibel above it comes out as 1/Ratio decibels.
+type Compressor struct {
+	ThresholdDB float64 // level in dBFS above which the audio is compressed
+	Ratio       float64 // at least 1; 1 leaves the audio unchanged
+	KneeDB      float64 // width of the soft knee around the threshold; 0 is a hard knee
+	Attack      float64 // seconds for the gain reduction to rise
+	Release     float64 // seconds for the gain reduction to fall back
+	MakeupDB    float64 // gain applied after compression
+}
+
+// Process compresses b.
+func (c Compressor) Process(b *audio.Buffer) (*audio.Buffer, []float64, error) {
+	if !(c.Ratio >= 1) {
+		return nil, nil, fmt.Errorf("ratio must be at least 1, got %g", c.Ratio)
+	}
+	if !(c.KneeDB >= 0) {
+		return nil, nil, fmt.Errorf("knee must not be negative, got %g", c.KneeDB)
+	}
+	if err := checkBallistics(c.Attack, c.Release); err != nil {
+		return nil, nil, err
+	}
+
+	slope := 1 - 1/c.Ratio
+	target := make([]float64, b.Frames())
+	for n, level := range frameLevels(b) {
+		over := GainToDB(level) - c.ThresholdDB
+
+		// the soft knee is a quadratic that joins the two straight parts
+		// of the curve, as described by Giannoulis, Massberg and Reiss
+		switch {
+		case 2*over < -c.KneeDB:
+			target[n] = 0
+		case c.KneeDB > 0 && 2*over <= c.KneeDB:
+			target[n] = slope * (over + c.KneeDB/2) * (over + c.KneeDB/2) / (2 * c.KneeDB)
+		default:
+			target[n] = slope * over
+		}
+	}
+
+	rate := float64(b.SampleRate)
+	reduction := smoothReduction(target, timeConstant(c.Attack, rate), timeConstant(c.Release, rate))
+
+	return applyReduction(b, reduction, c.MakeupDB), reduction, nil
+}
+
+// Expander is a downward expander, which turns down audio below
+// ThresholdDB so that each decibel below it comes out as Ratio decibels. An
+// infinite Ratio makes it a noise gate.
+type Expander struct {
+	ThresholdDB float64 // level in dBFS below which the audio is turned down
+	Ratio       float64 // at least 1; 1 leaves the audio unchanged
+	RangeDB     float64 // the largest gain reduction
+	Attack      float64 // seconds for the expander to open when the level rises
+	Release     float64 // seconds for it to close when the level falls
+}
+
+// Process expands b.
+func (e Expander) Process(b *audio.Buffer) (*audio.Buffer, []float64, error) {
+	if !(e.Ratio >= 1) {
+		return nil, nil, fmt.Errorf("ratio must be at least 1, got %g", e.Ratio)
+	}
+	if !(e.RangeDB >= 0 && !math.IsInf(e.RangeDB, 1)) {
+		return nil, nil, fmt.Errorf("range must be a finite number of decibels that is not negative, got %g", e.RangeDB)
+	}
+	if err := checkBallistics(e.Attack, e.Release); err != nil {
+		return nil, nil, err
+	}
+
+	target := make([]float64, b.Frames())
+	for n, level := range frameLevels(b) {
+		under := e.ThresholdDB - GainToDB(level)
+		if under > 0 && e.Ratio > 1 {
+			target[n] = math.Min(under*(e.Ratio-1), e.RangeDB)
+		}
+	}
+
+	// the reduction rises as the expander closes
+	rate := float64(b.SampleRate)
+	reduction := smoothReduction(target, timeConstant(e.Release, rate), timeConstant(e.Attack, rate))
+
+	return applyReduction(b, reduction, 0), reduction, nil
+}
+
+// Limit is a brickwall limiter: no sample of the result is louder than
+// ceilingDB. It looks ahead by lookahead seconds so that the gain is already
+// down when a peak arrives instead of clipping its start, and recovers over
+// release seconds. Since the whole buffer is available the lookahead adds no
+// latency. A lookahead longer than the buffer is shortened to its length.
+func Limit(b *audio.Buffer, ceilingDB, lookahead, release float64) (*audio.Buffer, []float64, error) {
+	if !(lookahead >= 0) {
+		return nil, nil, fmt.Errorf("lookahead must not be negative, got %g", lookahead)
+	}
+	if !(release >= 0) {
+		return nil, nil, fmt.Errorf("release must not be negative, got %g", release)
+	}
+
+	ceiling := DBToGain(ceilingDB)
+	levels := frameLevels(b)
+
+	// the gain that each frame needs on its own
+	required := make([]float64, len(levels))
+	for n, level := range levels {
+		required[n] = 1
+		if level > ceiling {
+			required[n] = ceiling / level
+		}
+	}
+
+	// The lowest gain required over the next window frames, averaged over
+	// the previous window frames, is at most the gain a frame requires when
+	// that frame is reached: the gain ramps down smoothly ahead of a peak.
+	window := int(math.Min(math.Round(lookahead*float64(b.SampleRate)), float64(len(levels)))) + 1
+	lowest := slidingMinimum(required, window)
+
+	gains := make([]float64, len(levels))
+	fall := timeConstant(release, float64(b.SampleRate))
+	sum := 0.0
+	prev := 1.0
+	for n := range gains {
+		sum += lowest[n]
+		if n >= window {
+			sum -= lowest[n-window]
+		}
+		// frames before the start need no reduction
+		smoothed := (sum + float64(max(0, window-n-1))) / float64(window)
+
+		g := smoothed
+		if g > prev {
+			g = fall*prev + (1-fall)*g
+		}
+		// guards against rounding in the running sum
+		g = math.Min(g, required[n])
+
+		gains[n] = g
+		prev = g
+	}
+
+	reduction := make([]float64, len(gains))
+	for n, g := range gains {
+		reduction[n] = max(0, -GainToDB(g))
+	}
+
+	samples := make([]float32, len(b.Samples))
+	for i, s := range b.Samples {
+		samples[i] = float32(float64(s) * gains[i/b.Channels])
+	}
+
+	return withSamples(b, samples), reduction, nil
+}
+
+// frameLevels returns the largest absolute sample of each frame of b.
+func frameLevels(b *audio.Buffer) []float64 {
+	levels := make([]float64, b.Frames())
+	for i, s := range b.Samples {
+		n := i / b.Channels
+		levels[n] = math.Max(levels[n], math.Abs(float64(s)))
+	}
+	return levels
+}
+
+// timeConstant returns the coefficient of a one-pole smoother that covers
+// 1 - 1/e of a step in secs seconds. Zero seconds gives an instant response.
+func timeConstant(secs, rate float64) float64 {
+	if secs <= 0 || rate <= 0 {
+		return 0
+	}
+	return math.Exp(-1 / (secs * rate))
+}
+
+// smoothReduction smooths a gain reduction in decibels, moving towards a
+// larger reduction with the coefficient rise and towards a smaller one with
+// fall.
+func smoothReduction(target []float64, rise, fall float64) []float64 {
+	reduction := make([]float64, len(target))
+	prev := 0.0
+	for n, t := range target {
+		coefficient := fall
+		if t > prev {
+			coefficient = rise
+		}
+		prev = coefficient*prev + (1-coefficient)*t
+		reduction[n] = prev
+	}
+	return reduction
+}
+
+// applyReduction turns each frame of b down by its reduction and up by
+// makeupDB.
+func applyReduction(b *audio.Buffer, reduction []float64, makeupDB float64) *audio.Buffer {
+	samples := make([]float32, len(b.Samples))
+	for i, s := range b.Samples {
+		samples[i] = float32(float64(s) * DBToGain(makeupDB-reduction[i/b.Channels]))
+	}
+	return withSamples(b, samples)
+}
+
+// slidingMinimum returns, for each index n, the smallest of the values at
+// n through n+window-1, using a queue of indices of increasing values.
+func slidingMinimum(values []float64, window int) []float64 {
+	minimum := make([]float64, len(values))
+	var queue []int
+
+	next := 0
+	for n := range values {
+		for ; next < len(values) && next < n+window; next++ {
+			for len(queue) > 0 && values[queue[len(queue)-1]] >= values[next] {
+				queue = queue[:len(queue)-1]
+			}
+			queue = append(queue, next)
+		}
+		for queue[0] < n {
+			queue = queue[1:]
+		}
+		minimum[n] = values[queue[0]]
+	}
+
+	return minimum
+}
+
+func checkBallistics(attack, release float64) error {
+	if !(attack >= 0) {
+		return fmt.Errorf("attack must not be negative, got %g", attack)
+	}
+	if !(release >= 0) {
+		return fmt.Errorf("release must not be negative, got %g", release)
+	}
+	return nil
+}
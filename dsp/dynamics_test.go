@@ -0,0 +1,231 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+	"wavy/audio"
+)
+
+// constantLevel returns stereo audio whose frames all peak at level dBFS.
+func constantLevel(level float64, frames int) *audio.Buffer {
+	b := &audio.Buffer{Samples: make([]float32, 2*frames), SampleRate: 1000, Channels: 2, BitDepth: 16}
+	for n := 0; n < frames; n++ {
+		b.Samples[2*n] = float32(DBToGain(level))
+		b.Samples[2*n+1] = float32(-DBToGain(level) / 2)
+	}
+	return b
+}
+
+func TestCompressor(t *testing.T) {
+	tests := []struct {
+		name       string
+		compressor Compressor
+		level      float64
+		reduction  float64
+	}{
+		{"above threshold", Compressor{ThresholdDB: -20, Ratio: 4}, -6, 10.5},
+		{"below threshold", Compressor{ThresholdDB: -20, Ratio: 4}, -30, 0},
+		{"ratio 1", Compressor{ThresholdDB: -20, Ratio: 1}, -6, 0},
+		{"infinite ratio", Compressor{ThresholdDB: -20, Ratio: math.Inf(1)}, -6, 14},
+		{"knee at threshold", Compressor{ThresholdDB: -20, Ratio: 4, KneeDB: 10}, -20, 0.9375},
+		{"below knee", Compressor{ThresholdDB: -20, Ratio: 4, KneeDB: 10}, -25.5, 0},
+		{"above knee", Compressor{ThresholdDB: -20, Ratio: 4, KneeDB: 10}, -10, 7.5},
+		{"makeup", Compressor{ThresholdDB: -20, Ratio: 2, MakeupDB: 6}, -10, 5},
+	}
+
+	for _, tt := range tests {
+		in := constantLevel(tt.level, 20)
+		out, reduction, err := tt.compressor.Process(in)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+
+		if len(reduction) != 20 {
+			t.Fatalf("%s: wrong envelope length. want=20, got=%d", tt.name, len(reduction))
+		}
+		for _, r := range reduction {
+			if math.Abs(r-tt.reduction) > 1e-5 {
+				t.Fatalf("%s: wrong gain reduction. want=%g, got=%g", tt.name, tt.reduction, r)
+			}
+		}
+
+		gain := DBToGain(tt.compressor.MakeupDB - tt.reduction)
+		for i, s := range out.Samples {
+			if want := float64(in.Samples[i]) * gain; math.Abs(float64(s)-want) > 1e-6 {
+				t.Fatalf("%s: wrong sample %d. want=%g, got=%g", tt.name, i, want, s)
+			}
+		}
+	}
+}
+
+func TestCompressorBallistics(t *testing.T) {
+	quiet, loud := constantLevel(-40, 100), constantLevel(0, 100)
+	in := &audio.Buffer{
+		Samples:    append(append(append([]float32{}, quiet.Samples...), loud.Samples...), quiet.Samples...),
+		SampleRate: 1000, Channels: 2, BitDepth: 16,
+	}
+
+	c := Compressor{ThresholdDB: -20, Ratio: 2, Attack: 0.01, Release: 0.05}
+	_, reduction, err := c.Process(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a time constant covers 1 - 1/e of a step: 10 frames of attack and 50
+	// of release at 1 kHz
+	tests := []struct {
+		frame    int
+		expected float64
+	}{
+		{99, 0},
+		{109, 10 * (1 - math.Exp(-1))},
+		{199, 10},
+		{249, 10 * math.Exp(-1)},
+	}
+
+	for _, tt := range tests {
+		if math.Abs(reduction[tt.frame]-tt.expected) > 0.01 {
+			t.Errorf("wrong gain reduction at frame %d. want=%g, got=%g", tt.frame, tt.expected, reduction[tt.frame])
+		}
+	}
+}
+
+func TestExpander(t *testing.T) {
+	tests := []struct {
+		name      string
+		expander  Expander
+		level     float64
+		reduction float64
+	}{
+		{"below threshold", Expander{ThresholdDB: -40, Ratio: 2, RangeDB: 80}, -50, 10},
+		{"above threshold", Expander{ThresholdDB: -40, Ratio: 2, RangeDB: 80}, -30, 0},
+		{"range", Expander{ThresholdDB: -40, Ratio: 4, RangeDB: 12}, -50, 12},
+		{"gate", Expander{ThresholdDB: -40, Ratio: math.Inf(1), RangeDB: 60}, -41, 60},
+		{"gate open", Expander{ThresholdDB: -40, Ratio: math.Inf(1), RangeDB: 60}, -39.9, 0},
+	}
+
+	for _, tt := range tests {
+		in := constantLevel(tt.level, 10)
+		out, reduction, err := tt.expander.Process(in)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+
+		for _, r := range reduction {
+			if math.Abs(r-tt.reduction) > 1e-5 {
+				t.Fatalf("%s: wrong gain reduction. want=%g, got=%g", tt.name, tt.reduction, r)
+			}
+		}
+
+		want := float64(in.Samples[0]) * DBToGain(-reduction[0])
+		if math.Abs(float64(out.Samples[0])-want) > 1e-9 {
+			t.Errorf("%s: wrong sample. want=%g, got=%g", tt.name, want, out.Samples[0])
+		}
+	}
+
+	// silence is turned down by the full range, not by infinity
+	_, reduction, _ := Expander{ThresholdDB: -40, Ratio: 2, RangeDB: 30}.Process(constantLevel(math.Inf(-1), 4))
+	if reduction[3] != 30 {
+		t.Errorf("wrong gain reduction of silence. want=30, got=%g", reduction[3])
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	in := sineBuffer(100, 8000, 8000, 2)
+	for c := 0; c < 2; c++ {
+		in.Samples[2*4000+c] = 2 // a +6 dB spike
+	}
+
+	out, reduction, err := Limit(in, -1, 0.005, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ceiling := DBToGain(-1)
+	if peak := Peak(out); peak > ceiling+1e-6 {
+		t.Errorf("limiter let through a peak of %g above the ceiling %g", peak, ceiling)
+	}
+	if math.Abs(float64(out.Samples[2*4000])-ceiling) > 1e-6 {
+		t.Errorf("spike should be limited to the ceiling. got=%g", out.Samples[2*4000])
+	}
+
+	// the sine peaks at 0.5, so only the spike and the release after it are
+	// turned down
+	if reduction[3000] != 0 || reduction[7000] > 0.01 {
+		t.Errorf("quiet frames were turned down: %g and %g", reduction[3000], reduction[7000])
+	}
+
+	// the gain ramps down over the 40-frame lookahead before the spike and
+	// recovers afterwards
+	want := 20*math.Log10(2) + 1
+	if math.Abs(reduction[4000]-want) > 1e-9 {
+		t.Errorf("wrong gain reduction at the spike. want=%g, got=%g", want, reduction[4000])
+	}
+	if !(reduction[3959] == 0 && reduction[3980] > 0 && reduction[3980] < reduction[4000]) {
+		t.Errorf("gain should ramp down before the spike. got=%g, %g, %g",
+			reduction[3959], reduction[3980], reduction[4000])
+	}
+	if !(reduction[4010] > 0 && reduction[4010] < reduction[4000]) {
+		t.Errorf("gain should recover after the spike. got=%g", reduction[4010])
+	}
+
+	// without lookahead only the spike itself is turned down at first
+	_, reduction, _ = Limit(in, -1, 0, 0)
+	if reduction[3999] != 0 || reduction[4001] != 0 {
+		t.Errorf("limiter without lookahead or release turned down neighbouring frames")
+	}
+
+	// a lookahead far longer than the buffer acts as one as long as it
+	long, _, err := Limit(in, -1, 1e21, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	same, _, _ := Limit(in, -1, 1, 0.05)
+	testSamples(t, "long lookahead", same.Samples, long.Samples)
+	if peak := Peak(long); peak > ceiling+1e-6 {
+		t.Errorf("limiter with a long lookahead let through a peak of %g", peak)
+	}
+}
+
+func TestSlidingMinimum(t *testing.T) {
+	values := []float64{5, 3, 8, 1, 9, 2, 7, 7, 4, 6}
+
+	for window := 1; window <= 12; window++ {
+		got := slidingMinimum(values, window)
+		for n := range values {
+			want := values[n]
+			for k := n; k < n+window && k < len(values); k++ {
+				want = math.Min(want, values[k])
+			}
+			if got[n] != want {
+				t.Errorf("window %d: wrong minimum at %d. want=%g, got=%g", window, n, want, got[n])
+			}
+		}
+	}
+}
+
+func TestDynamicsErrors(t *testing.T) {
+	b := constantLevel(0, 1)
+	errorOf := func(_ *audio.Buffer, _ []float64, err error) error {
+		return err
+	}
+
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{errorOf(Compressor{Ratio: 0.5}.Process(b)), "ratio must be at least 1, got 0.5"},
+		{errorOf(Compressor{Ratio: 2, KneeDB: -1}.Process(b)), "knee must not be negative, got -1"},
+		{errorOf(Compressor{Ratio: 2, Attack: -1}.Process(b)), "attack must not be negative, got -1"},
+		{errorOf(Expander{Ratio: 2, Release: -2}.Process(b)), "release must not be negative, got -2"},
+		{errorOf(Expander{Ratio: 2, RangeDB: -6}.Process(b)), "range must be a finite number of decibels that is not negative, got -6"},
+		{errorOf(Limit(b, -1, -0.1, 0)), "lookahead must not be negative, got -0.1"},
+		{errorOf(Limit(b, -1, 0, -0.1)), "release must not be negative, got -0.1"},
+	}
+
+	for _, tt := range tests {
+		if tt.err == nil || tt.err.Error() != tt.expected {
+			t.Errorf("wrong error. want=%q, got=%v", tt.expected, tt.err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+	"wavy/audio"
+)
+
+// Concat joins buffers end to end. With a positive crossfade the end of
+// each buffer overlaps the start of the next by that many seconds, or by
+// the length of the shorter of the two if that is less, with an
+// equal-power fade that keeps the level steady across uncorrelated
+// material. All buffers must have the same sample rate and number of
+// channels; the result has the largest bit depth among them.
+func Concat(buffers []*audio.Buffer, crossfade float64) (*audio.Buffer, error) {
+	if len(buffers) == 0 {
+		return nil, fmt.Errorf("nothing to concatenate")
+	}
+	if !(crossfade >= 0) {
+		return nil, fmt.Errorf("crossfade must not be negative, got %g", crossfade)
+	}
+
+	first := buffers[0]
+	out := &audio.Buffer{
+		Samples:    append([]float32{}, first.Samples...),
+		SampleRate: first.SampleRate,
+		Channels:   first.Channels,
+		BitDepth:   first.BitDepth,
+	}
+
+	for i, b := range buffers[1:] {
+		if b.SampleRate != out.SampleRate {
+			return nil, fmt.Errorf("mismatched sample rates: %dHz and %dHz", out.SampleRate, b.SampleRate)
+		}
+		if b.Channels != out.Channels {
+			return nil, fmt.Errorf("mismatched channel counts: %d and %d", out.Channels, b.Channels)
+		}
+		out.BitDepth = max(out.BitDepth, b.BitDepth)
+
+		// seconds is limited to the length of b, and the fade must not
+		// reach back past the start of the buffer before it either
+		overlap := min(seconds(b, crossfade), buffers[i].Frames())
+		start := len(out.Samples) - overlap*out.Channels
+
+		for i := 0; i < overlap*out.Channels; i++ {
+			t := (float64(i/out.Channels) + 0.5) / float64(overlap) * math.Pi / 2
+			mixed := float64(out.Samples[start+i])*math.Cos(t) + float64(b.Samples[i])*math.Sin(t)
+			out.Samples[start+i] = float32(mixed)
+		}
+		out.Samples = append(out.Samples, b.Samples[overlap*out.Channels:]...)
+	}
+
+	return out, nil
+}
+
+// A Region is a span of frames of a buffer, from Start up to but not
+// including End.
+type Region struct {
+	Start, End int
+}
+
+// DetectSilence returns the regions of b, in order, where every sample is
+// quieter than thresholdDB for at least minLength seconds.
+func DetectSilence(b *audio.Buffer, thresholdDB, minLength float64) ([]Region, error) {
+	if !(minLength >= 0) {
+		return nil, fmt.Errorf("minimum silence length must not be negative, got %g", minLength)
+	}
+	if math.IsInf(minLength, 1) {
+		return nil, fmt.Errorf("minimum silence length must be finite, got %g", minLength)
+	}
+
+	threshold := DBToGain(thresholdDB)
+	// one frame longer than b is as good as any longer minimum: nothing matches
+	minFrames := max(1, int(math.Min(math.Round(minLength*float64(b.SampleRate)), float64(b.Frames()+1))))
+
+	var regions []Region
+	start := -1
+	levels := frameLevels(b)
+	for n := 0; n <= len(levels); n++ {
+		quiet := n < len(levels) && levels[n] < threshold
+		switch {
+		case quiet && start < 0:
+			start = n
+		case !quiet && start >= 0:
+			if n-start >= minFrames {
+				regions = append(regions, Region{Start: start, End: n})
+			}
+			start = -1
+		}
+	}
+
+	return regions, nil
+}
+
+// SplitOnSilence cuts b at each silence found by DetectSilence and returns
+// the sounds in between, leaving the silences out.
+func SplitOnSilence(b *audio.Buffer, thresholdDB, minLength float64) ([]*audio.Buffer, error) {
+	silences, err := DetectSilence(b, thresholdDB, minLength)
+	if err != nil {
+		return nil, err
+	}
+
+	var clips []*audio.Buffer
+	start := 0
+	for _, silence := range append(silences, Region{Start: b.Frames(), End: b.Frames()}) {
+		if silence.Start > start {
+			clips = append(clips, frames(b, start, silence.Start))
+		}
+		start = silence.End
+	}
+
+	return clips, nil
+}
+
+// TrimSilence removes the frames at the start and end of b in which every
+// sample is quieter than thresholdDB. Audio that is silent throughout
+// trims to nothing.
+func TrimSilence(b *audio.Buffer, thresholdDB float64) *audio.Buffer {
+	threshold := DBToGain(thresholdDB)
+	levels := frameLevels(b)
+
+	start, end := 0, len(levels)
+	for start < end && levels[start] < threshold {
+		start++
+	}
+	for end > start && levels[end-1] < threshold {
+		end--
+	}
+
+	return frames(b, start, end)
+}
+
+// frames returns a copy of frames start up to end of b.
+func frames(b *audio.Buffer, start, end int) *audio.Buffer {
+	return withSamples(b, append([]float32{}, b.Samples[start*b.Channels:end*b.Channels]...))
+}
@@ -0,0 +1,138 @@
+package dsp
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"wavy/audio"
+)
+
+func TestConcat(t *testing.T) {
+	a := &audio.Buffer{Samples: []float32{1, 1, 1, 1}, SampleRate: 4, Channels: 1, BitDepth: 16}
+	b := &audio.Buffer{Samples: []float32{-1, -1}, SampleRate: 4, Channels: 1, BitDepth: 24}
+	stereo := &audio.Buffer{Samples: []float32{1, 2, 3, 4}, SampleRate: 4, Channels: 2, BitDepth: 16}
+
+	joined := must(Concat([]*audio.Buffer{a, b, a}, 0))
+	testSamples(t, "concat", []float32{1, 1, 1, 1, -1, -1, 1, 1, 1, 1}, joined.Samples)
+	if joined.BitDepth != 24 {
+		t.Errorf("concat should keep the largest bit depth. want=24, got=%d", joined.BitDepth)
+	}
+
+	testSamples(t, "concat stereo", []float32{1, 2, 3, 4, 1, 2, 3, 4},
+		must(Concat([]*audio.Buffer{stereo, stereo}, 0)).Samples)
+	testSamples(t, "concat one", a.Samples, must(Concat([]*audio.Buffer{a}, 1)).Samples)
+
+	// a 0.5 second crossfade overlaps two frames, faded at π/8 and 3π/8
+	c1, s1 := math.Cos(math.Pi/8), math.Sin(math.Pi/8)
+	c3, s3 := math.Cos(3*math.Pi/8), math.Sin(3*math.Pi/8)
+	testSamples(t, "crossfade", []float32{1, 1, float32(c1 - s1), float32(c3 - s3)},
+		must(Concat([]*audio.Buffer{a, b}, 0.5)).Samples)
+
+	// the overlap is no longer than the shorter buffer
+	if got := must(Concat([]*audio.Buffer{a, b}, 10)).Frames(); got != 4 {
+		t.Errorf("crossfade longer than a buffer: want=4 frames, got=%d", got)
+	}
+
+	// a short buffer in the middle limits both of its fades, so the second
+	// one does not reach back into the first buffer
+	around := must(Concat([]*audio.Buffer{a, b, a}, 10))
+	if around.Frames() != 6 {
+		t.Errorf("crossfade around a short buffer: want=6 frames, got=%d", around.Frames())
+	}
+	testSamples(t, "start of the first buffer", []float32{1, 1}, around.Samples[:2])
+
+	testSamples(t, "input", []float32{1, 1, 1, 1}, a.Samples)
+}
+
+func TestSilence(t *testing.T) {
+	// sound, 3 frames of silence, sound, 1 frame of silence, sound, 2 frames of silence
+	b := &audio.Buffer{
+		Samples:    []float32{0.5, 0, 0.001, 0, -0.5, 0.5, 0, 0.5, 0, 0},
+		SampleRate: 2, Channels: 1, BitDepth: 16,
+	}
+
+	tests := []struct {
+		minLength float64
+		expected  []Region
+	}{
+		{0, []Region{{1, 4}, {6, 7}, {8, 10}}},
+		{1, []Region{{1, 4}, {8, 10}}},
+		{1.5, []Region{{1, 4}}},
+		{2, nil},
+	}
+
+	for _, tt := range tests {
+		regions := mustValue(DetectSilence(b, -40, tt.minLength))
+		if !reflect.DeepEqual(regions, tt.expected) {
+			t.Errorf("silences at least %gs long wrong. want=%v, got=%v", tt.minLength, tt.expected, regions)
+		}
+	}
+
+	// the 0.001 sample is above -70 dB and breaks the silence up
+	if regions := mustValue(DetectSilence(b, -70, 1)); !reflect.DeepEqual(regions, []Region{{8, 10}}) {
+		t.Errorf("silences below -70 dB wrong. got=%v", regions)
+	}
+
+	clips := mustValue(SplitOnSilence(b, -40, 1))
+	if len(clips) != 2 {
+		t.Fatalf("wrong number of clips. want=2, got=%d", len(clips))
+	}
+	testSamples(t, "first clip", []float32{0.5}, clips[0].Samples)
+	testSamples(t, "second clip", []float32{-0.5, 0.5, 0, 0.5}, clips[1].Samples)
+
+	testSamples(t, "trim", []float32{0.5, 0, 0.001, 0, -0.5, 0.5, 0, 0.5}, TrimSilence(b, -40).Samples)
+
+	stereo := &audio.Buffer{Samples: []float32{0, 0, 0, 0.5, 0.5, 0, 0, 0}, SampleRate: 2, Channels: 2}
+	testSamples(t, "trim stereo", []float32{0, 0.5, 0.5, 0}, TrimSilence(stereo, -40).Samples)
+
+	silent := &audio.Buffer{Samples: make([]float32, 4), SampleRate: 2, Channels: 1}
+	if got := TrimSilence(silent, -40).Frames(); got != 0 {
+		t.Errorf("silence should trim to nothing, got %d frames", got)
+	}
+	if clips := mustValue(SplitOnSilence(silent, -40, 0)); len(clips) != 0 {
+		t.Errorf("silence should split into no clips, got %d", len(clips))
+	}
+	if regions := mustValue(DetectSilence(silent, -40, 2)); !reflect.DeepEqual(regions, []Region{{0, 4}}) {
+		t.Errorf("silence as long as the buffer wrong. got=%v", regions)
+	}
+	if regions := mustValue(DetectSilence(silent, -40, 1e300)); regions != nil {
+		t.Errorf("silence longer than the buffer should find nothing, got %v", regions)
+	}
+}
+
+func TestEditErrors(t *testing.T) {
+	a := &audio.Buffer{Samples: []float32{0}, SampleRate: 8000, Channels: 1}
+	stereo := &audio.Buffer{Samples: []float32{0, 0}, SampleRate: 8000, Channels: 2}
+	other := &audio.Buffer{Samples: []float32{0}, SampleRate: 44100, Channels: 1}
+
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{errorOf(Concat(nil, 0)), "nothing to concatenate"},
+		{errorOf(Concat([]*audio.Buffer{a, other}, 0)), "mismatched sample rates: 8000Hz and 44100Hz"},
+		{errorOf(Concat([]*audio.Buffer{a, stereo}, 0)), "mismatched channel counts: 1 and 2"},
+		{errorOf(Concat([]*audio.Buffer{a, a}, -1)), "crossfade must not be negative, got -1"},
+		{errorOfAny(SplitOnSilence(a, -60, -1)), "minimum silence length must not be negative, got -1"},
+		{errorOfAny(DetectSilence(a, -60, math.Inf(1))), "minimum silence length must be finite, got +Inf"},
+	}
+
+	for _, tt := range tests {
+		if tt.err == nil || tt.err.Error() != tt.expected {
+			t.Errorf("wrong error. want=%q, got=%v", tt.expected, tt.err)
+		}
+	}
+}
+
+// errorOfAny and mustValue are errorOf and must for results other than a
+// single buffer.
+func errorOfAny[T any](_ T, err error) error {
+	return err
+}
+
+func mustValue[T any](value T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
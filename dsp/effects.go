@@ -0,0 +1,88 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+	"wavy/audio"
+)
+
+// Gain changes the level of b by db decibels.
+func Gain(b *audio.Buffer, db float64) *audio.Buffer {
+	return scale(b, DBToGain(db))
+}
+
+// FadeIn ramps the level of the first secs seconds of b up linearly from
+// silence. A fade longer than b covers all of it.
+func FadeIn(b *audio.Buffer, secs float64) (*audio.Buffer, error) {
+	if secs < 0 {
+		return nil, fmt.Errorf("fade length must not be negative, got %g", secs)
+	}
+
+	out := scale(b, 1)
+	n := seconds(b, secs)
+	for i := 0; i < n; i++ {
+		fadeFrame(out, i, float64(i)/float64(n))
+	}
+
+	return out, nil
+}
+
+// FadeOut ramps the level of the last secs seconds of b down linearly to
+// silence, so that the last frame is silent. A fade longer than b covers all
+// of it.
+func FadeOut(b *audio.Buffer, secs float64) (*audio.Buffer, error) {
+	if secs < 0 {
+		return nil, fmt.Errorf("fade length must not be negative, got %g", secs)
+	}
+
+	out := scale(b, 1)
+	n := seconds(b, secs)
+	last := b.Frames() - 1
+	for i := 0; i < n; i++ {
+		fadeFrame(out, last-i, float64(i)/float64(n))
+	}
+
+	return out, nil
+}
+
+func fadeFrame(b *audio.Buffer, frame int, gain float64) {
+	for c := 0; c < b.Channels; c++ {
+		i := frame*b.Channels + c
+		b.Samples[i] = float32(float64(b.Samples[i]) * gain)
+	}
+}
+
+// Peak returns the largest absolute sample value of b.
+func Peak(b *audio.Buffer) float64 {
+	peak := 0.0
+	for _, s := range b.Samples {
+		peak = math.Max(peak, math.Abs(float64(s)))
+	}
+
+	return peak
+}
+
+// Normalize scales b so that its peak is at peakDB decibels relative to full
+// scale. Silence is returned unchanged.
+func Normalize(b *audio.Buffer, peakDB float64) *audio.Buffer {
+	peak := Peak(b)
+	if peak == 0 {
+		return scale(b, 1)
+	}
+
+	return scale(b, DBToGain(peakDB)/peak)
+}
+
+// Reverse returns b played backwards. The channels of each frame stay in
+// order.
+func Reverse(b *audio.Buffer) *audio.Buffer {
+	samples := make([]float32, len(b.Samples))
+
+	frames := b.Frames()
+	for i := 0; i < frames; i++ {
+		j := frames - 1 - i
+		copy(samples[j*b.Channels:(j+1)*b.Channels], b.Samples[i*b.Channels:(i+1)*b.Channels])
+	}
+
+	return withSamples(b, samples)
+}
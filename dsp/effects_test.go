@@ -0,0 +1,123 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+	"wavy/audio"
+)
+
+func TestEffects(t *testing.T) {
+	mono := &audio.Buffer{
+		Samples:    []float32{0.5, -0.25, 0.5, 1},
+		SampleRate: 4, Channels: 1, BitDepth: 16,
+	}
+	stereo := &audio.Buffer{
+		Samples:    []float32{0.5, -0.5, 0.25, -0.25, 1, -1},
+		SampleRate: 4, Channels: 2, BitDepth: 24,
+	}
+
+	tests := []struct {
+		name     string
+		result   *audio.Buffer
+		expected []float32
+	}{
+		{"gain +6dB", Gain(mono, GainToDB(2)), []float32{1, -0.5, 1, 2}},
+		{"gain -6dB", Gain(stereo, GainToDB(0.5)), []float32{0.25, -0.25, 0.125, -0.125, 0.5, -0.5}},
+		{"fade in", must(FadeIn(mono, 0.5)), []float32{0, -0.125, 0.5, 1}},
+		{"fade in too long", must(FadeIn(mono, 10)), []float32{0, -0.0625, 0.25, 0.75}},
+		{"fade in stereo", must(FadeIn(stereo, 0.5)), []float32{0, 0, 0.125, -0.125, 1, -1}},
+		{"fade out", must(FadeOut(mono, 0.5)), []float32{0.5, -0.25, 0.25, 0}},
+		{"fade out stereo", must(FadeOut(stereo, 0.25)), []float32{0.5, -0.5, 0.25, -0.25, 0, 0}},
+		{"normalize", Normalize(mono, GainToDB(0.5)), []float32{0.25, -0.125, 0.25, 0.5}},
+		{"normalize stereo", Normalize(stereo, 0), []float32{0.5, -0.5, 0.25, -0.25, 1, -1}},
+		{"reverse", Reverse(mono), []float32{1, 0.5, -0.25, 0.5}},
+		{"reverse stereo", Reverse(stereo), []float32{1, -1, 0.25, -0.25, 0.5, -0.5}},
+	}
+
+	for _, tt := range tests {
+		source := mono
+		if tt.result.Channels == 2 {
+			source = stereo
+		}
+		if tt.result.SampleRate != source.SampleRate || tt.result.BitDepth != source.BitDepth {
+			t.Errorf("%s: format changed. got=%dHz %d-bit", tt.name,
+				tt.result.SampleRate, tt.result.BitDepth)
+		}
+
+		testSamples(t, tt.name, tt.expected, tt.result.Samples)
+	}
+
+	// the inputs are unchanged
+	testSamples(t, "mono input", []float32{0.5, -0.25, 0.5, 1}, mono.Samples)
+	testSamples(t, "stereo input", []float32{0.5, -0.5, 0.25, -0.25, 1, -1}, stereo.Samples)
+}
+
+func TestEffectErrors(t *testing.T) {
+	b := &audio.Buffer{Samples: []float32{0}, SampleRate: 8000, Channels: 1}
+	surround := &audio.Buffer{Samples: make([]float32, 6), SampleRate: 8000, Channels: 6}
+
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{errorOf(FadeIn(b, -1)), "fade length must not be negative, got -1"},
+		{errorOf(FadeOut(b, -0.5)), "fade length must not be negative, got -0.5"},
+		{errorOf(Pan(b, 1.5)), "pan position must be between -1 and 1, got 1.5"},
+		{errorOf(Pan(surround, 0)), "cannot pan 6-channel audio"},
+		{errorOf(Resample(b, 0)), "sample rate must be positive, got 0"},
+		{errorOf(Resample(b, 1000000000000000)), "resampling to 1000000000000000Hz gives too many samples"},
+	}
+
+	for _, tt := range tests {
+		if tt.err == nil || tt.err.Error() != tt.expected {
+			t.Errorf("wrong error. want=%q, got=%v", tt.expected, tt.err)
+		}
+	}
+}
+
+func TestDecibels(t *testing.T) {
+	tests := []struct {
+		db   float64
+		gain float64
+	}{
+		{0, 1},
+		{20, 10},
+		{-40, 0.01},
+		{math.Inf(-1), 0},
+	}
+
+	for _, tt := range tests {
+		if got := DBToGain(tt.db); math.Abs(got-tt.gain) > 1e-12 {
+			t.Errorf("DBToGain(%g) wrong. want=%g, got=%g", tt.db, tt.gain, got)
+		}
+		if got := GainToDB(tt.gain); math.Abs(got-tt.db) > 1e-12 && got != tt.db {
+			t.Errorf("GainToDB(%g) wrong. want=%g, got=%g", tt.gain, tt.db, got)
+		}
+	}
+}
+
+func must(b *audio.Buffer, err error) *audio.Buffer {
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func errorOf(_ *audio.Buffer, err error) error {
+	return err
+}
+
+func testSamples(t *testing.T, name string, expected, actual []float32) {
+	t.Helper()
+
+	if len(actual) != len(expected) {
+		t.Errorf("%s: wrong number of samples. want=%d, got=%d", name, len(expected), len(actual))
+		return
+	}
+
+	for i, want := range expected {
+		if math.Abs(float64(actual[i]-want)) > 1e-6 {
+			t.Errorf("%s: wrong sample %d. want=%g, got=%g", name, i, want, actual[i])
+		}
+	}
+}
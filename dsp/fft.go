@@ -0,0 +1,261 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// FFT returns the discrete Fourier transform of x:
+//
+//	X[k] = sum over n of x[n] * exp(-2πi*k*n/N)
+//
+// Lengths that are a power of two use an iterative radix-2 transform, and
+// any other length uses Bluestein's algorithm, which rewrites the transform
+// as a convolution computed with power-of-two transforms. Both take
+// O(N log N) time.
+func FFT(x []complex128) []complex128 {
+	out := append([]complex128{}, x...)
+	transform(out, false)
+	return out
+}
+
+// IFFT returns the inverse of FFT, including the 1/N scaling, so that
+// IFFT(FFT(x)) is x up to rounding.
+func IFFT(x []complex128) []complex128 {
+	out := append([]complex128{}, x...)
+	transform(out, true)
+
+	scale := complex(1/float64(len(out)), 0)
+	for i := range out {
+		out[i] *= scale
+	}
+
+	return out
+}
+
+// transform replaces x with its unscaled forward or inverse transform.
+func transform(x []complex128, inverse bool) {
+	n := len(x)
+	switch {
+	case n <= 1:
+		return
+	case n&(n-1) == 0:
+		radix2(x, inverse)
+	default:
+		bluestein(x, inverse)
+	}
+}
+
+// radix2 is the in-place Cooley-Tukey transform for power-of-two lengths.
+func radix2(x []complex128, inverse bool) {
+	n := len(x)
+	shift := 64 - bits.TrailingZeros(uint(n))
+
+	for i := range x {
+		j := int(bits.Reverse64(uint64(i)) >> shift)
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+
+	for size := 2; size <= n; size *= 2 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even := x[start+k]
+				odd := w * x[start+k+size/2]
+				x[start+k] = even + odd
+				x[start+k+size/2] = even - odd
+				w *= step
+			}
+		}
+	}
+}
+
+// bluestein computes a transform of any length n with the identity
+// k*n = (k² + n² - (k-n)²) / 2, which turns it into a convolution with the
+// chirp exp(±πi*n²/N). The convolution is done with radix-2 transforms of a
+// length of at least 2N-1.
+func bluestein(x []complex128, inverse bool) {
+	n := len(x)
+	m := 1 << bits.Len(uint(2*n-2))
+
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+
+	// n² is reduced modulo 2N before the multiplication by π/N to keep the
+	// angle small and the chirp accurate for long inputs
+	chirp := make([]complex128, n)
+	for i := range chirp {
+		angle := sign * math.Pi * float64((i*i)%(2*n)) / float64(n)
+		chirp[i] = cmplx.Rect(1, angle)
+	}
+
+	a := make([]complex128, m)
+	for i := range x {
+		a[i] = x[i] * chirp[i]
+	}
+
+	b := make([]complex128, m)
+	b[0] = cmplx.Conj(chirp[0])
+	for i := 1; i < n; i++ {
+		b[i] = cmplx.Conj(chirp[i])
+		b[m-i] = b[i]
+	}
+
+	radix2(a, false)
+	radix2(b, false)
+	for i := range a {
+		a[i] *= b[i]
+	}
+	radix2(a, true)
+
+	scale := complex(1/float64(m), 0)
+	for i := range x {
+		x[i] = a[i] * scale * chirp[i]
+	}
+}
+
+// A Window returns the coefficients of a window function of the given size,
+// which taper a frame of samples to reduce spectral leakage.
+type Window func(size int) []float64
+
+// Hann is the raised cosine window, a good default for spectrograms.
+func Hann(size int) []float64 {
+	return cosineWindow(size, 0.5, 0.5, 0)
+}
+
+// Hamming is like Hann but does not reach zero at its ends, trading wider
+// side lobes far from a peak for a lower first side lobe.
+func Hamming(size int) []float64 {
+	return cosineWindow(size, 0.54, 0.46, 0)
+}
+
+// Blackman has lower side lobes than Hann and Hamming and a wider main lobe.
+func Blackman(size int) []float64 {
+	return cosineWindow(size, 0.42, 0.5, 0.08)
+}
+
+// cosineWindow returns the periodic window a0 - a1*cos(2πn/N) + a2*cos(4πn/N),
+// which is the usual choice for spectral analysis.
+func cosineWindow(size int, a0, a1, a2 float64) []float64 {
+	w := make([]float64, size)
+	for i := range w {
+		phase := 2 * math.Pi * float64(i) / float64(size)
+		w[i] = a0 - a1*math.Cos(phase) + a2*math.Cos(2*phase)
+	}
+	return w
+}
+
+// WindowByName returns the window called "hann", "hamming" or "blackman".
+func WindowByName(name string) (Window, error) {
+	switch name {
+	case "hann":
+		return Hann, nil
+	case "hamming":
+		return Hamming, nil
+	case "blackman":
+		return Blackman, nil
+	default:
+		return nil, fmt.Errorf("unknown window %q", name)
+	}
+}
+
+// Spectrogram splits x into frames of size samples that start hop samples
+// apart, multiplies each by window and returns the magnitudes of the
+// non-negative frequency bins of its FFT: size/2+1 values per frame, bin k
+// being k*rate/size hertz. Samples at the end that do not fill a whole
+// frame are left out.
+func Spectrogram(x []float64, size, hop int, window Window) ([][]float64, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("window size must be positive, got %d", size)
+	}
+	if hop <= 0 {
+		return nil, fmt.Errorf("hop must be positive, got %d", hop)
+	}
+
+	frames := [][]float64{}
+	if size > len(x) {
+		return frames, nil
+	}
+
+	w := window(size)
+	frame := make([]complex128, size)
+
+	for start := 0; start <= len(x)-size; start += hop {
+		for i := range frame {
+			frame[i] = complex(x[start+i]*w[i], 0)
+		}
+		transform(frame, false)
+
+		magnitudes := make([]float64, size/2+1)
+		for k := range magnitudes {
+			magnitudes[k] = cmplx.Abs(frame[k])
+		}
+		frames = append(frames, magnitudes)
+
+		// stops before start += hop can overflow
+		if hop > len(x)-size-start {
+			break
+		}
+	}
+
+	return frames, nil
+}
+
+// PeakFrequency returns the frequency in hertz of the strongest component
+// of x, sampled at rate, ignoring the constant (DC) component. The signal is
+// Hann-windowed, and the peak is located between FFT bins by fitting a
+// parabola to the log magnitudes around it. It returns 0 for silence and
+// for signals shorter than four samples.
+func PeakFrequency(x []float64, rate float64) float64 {
+	n := len(x)
+	if n < 4 {
+		return 0
+	}
+
+	w := Hann(n)
+	frame := make([]complex128, n)
+	for i := range frame {
+		frame[i] = complex(x[i]*w[i], 0)
+	}
+	transform(frame, false)
+
+	magnitudes := make([]float64, n/2+1)
+	for k := range magnitudes {
+		magnitudes[k] = cmplx.Abs(frame[k])
+	}
+
+	// the window spreads DC into bin 1, so the search starts at bin 2
+	peak := 2
+	for k := 2; k < len(magnitudes); k++ {
+		if magnitudes[k] > magnitudes[peak] {
+			peak = k
+		}
+	}
+	if magnitudes[peak] == 0 {
+		return 0
+	}
+
+	offset := 0.0
+	if peak+1 < len(magnitudes) {
+		l := math.Log(magnitudes[peak-1] + 1e-300)
+		c := math.Log(magnitudes[peak])
+		r := math.Log(magnitudes[peak+1] + 1e-300)
+		if d := l - 2*c + r; d < 0 {
+			offset = 0.5 * (l - r) / d
+		}
+	}
+
+	return (float64(peak) + offset) * rate / float64(n)
+}
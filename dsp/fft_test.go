@@ -0,0 +1,181 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+func naiveDFT(x []complex128) []complex128 {
+	n := len(x)
+	out := make([]complex128, n)
+	for k := range out {
+		for i, v := range x {
+			out[k] += v * cmplx.Rect(1, -2*math.Pi*float64(k*i)/float64(n))
+		}
+	}
+	return out
+}
+
+func TestFFTMatchesDFT(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	// powers of two use radix-2 and everything else Bluestein
+	for _, n := range []int{1, 2, 3, 4, 5, 7, 8, 12, 17, 64, 100, 127, 256, 1000} {
+		x := make([]complex128, n)
+		for i := range x {
+			x[i] = complex(rng.Float64()*2-1, rng.Float64()*2-1)
+		}
+
+		want := naiveDFT(x)
+		got := FFT(x)
+
+		for k := range want {
+			if cmplx.Abs(got[k]-want[k]) > 1e-9*float64(n) {
+				t.Errorf("n=%d: wrong bin %d. want=%v, got=%v", n, k, want[k], got[k])
+				break
+			}
+		}
+
+		back := IFFT(got)
+		for i := range x {
+			if cmplx.Abs(back[i]-x[i]) > 1e-12*float64(n) {
+				t.Errorf("n=%d: IFFT(FFT(x)) differs at %d. want=%v, got=%v", n, i, x[i], back[i])
+				break
+			}
+		}
+	}
+}
+
+func TestFFTDoesNotModifyInput(t *testing.T) {
+	x := []complex128{1, 2, 3}
+	FFT(x)
+	IFFT(x)
+
+	if x[0] != 1 || x[1] != 2 || x[2] != 3 {
+		t.Errorf("input was modified: %v", x)
+	}
+}
+
+func TestWindows(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []float64
+	}{
+		{"hann", []float64{0, 0.5, 1, 0.5}},
+		{"hamming", []float64{0.08, 0.54, 1, 0.54}},
+		{"blackman", []float64{0, 0.34, 1, 0.34}},
+	}
+
+	for _, tt := range tests {
+		window, err := WindowByName(tt.name)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+
+		for i, v := range window(4) {
+			if math.Abs(v-tt.expected[i]) > 1e-12 {
+				t.Errorf("%s: wrong coefficient %d. want=%g, got=%g", tt.name, i, tt.expected[i], v)
+			}
+		}
+	}
+
+	if _, err := WindowByName("kaiser"); err == nil || err.Error() != `unknown window "kaiser"` {
+		t.Errorf("wrong error for unknown window: %v", err)
+	}
+}
+
+func TestSpectrogram(t *testing.T) {
+	// 1 kHz at 8 kHz falls exactly on bin 32 of a 256-point frame
+	x := make([]float64, 8000)
+	for i := range x {
+		x[i] = math.Sin(2 * math.Pi * 1000 * float64(i) / 8000)
+	}
+
+	frames, err := Spectrogram(x, 256, 128, Hann)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(frames) != (8000-256)/128+1 {
+		t.Errorf("wrong number of frames. got=%d", len(frames))
+	}
+
+	for i, frame := range frames {
+		if len(frame) != 129 {
+			t.Fatalf("frame %d: wrong number of bins. got=%d", i, len(frame))
+		}
+
+		peak := 0
+		for k := range frame {
+			if frame[k] > frame[peak] {
+				peak = k
+			}
+		}
+		if peak != 32 {
+			t.Errorf("frame %d: peak in bin %d, want 32", i, peak)
+		}
+
+		// a Hann window halves the amplitude, and a real sine puts half of
+		// its energy in the positive frequencies: 256 * 0.5 * 0.5
+		if math.Abs(frame[32]-64) > 1e-9 {
+			t.Errorf("frame %d: wrong magnitude. want=64, got=%g", i, frame[32])
+		}
+	}
+
+	short, _ := Spectrogram(x[:100], 256, 128, Hann)
+	if len(short) != 0 {
+		t.Errorf("input shorter than the window gave %d frames", len(short))
+	}
+	if huge, err := Spectrogram(x[:3], 1000000000000000, 1, Hann); err != nil || len(huge) != 0 {
+		t.Errorf("a window too large to allocate gave %d frames, %v", len(huge), err)
+	}
+	if one, err := Spectrogram(x[:4], 2, math.MaxInt, Hann); err != nil || len(one) != 1 {
+		t.Errorf("a hop too large to add gave %d frames, %v", len(one), err)
+	}
+
+	tests := []struct {
+		size, hop int
+		expected  string
+	}{
+		{0, 1, "window size must be positive, got 0"},
+		{256, -1, "hop must be positive, got -1"},
+	}
+	for _, tt := range tests {
+		if _, err := Spectrogram(x, tt.size, tt.hop, Hann); err == nil || err.Error() != tt.expected {
+			t.Errorf("wrong error. want=%q, got=%v", tt.expected, err)
+		}
+	}
+}
+
+func TestPeakFrequency(t *testing.T) {
+	tests := []struct {
+		freq   float64
+		rate   float64
+		length int
+	}{
+		{440, 44100, 44100},
+		{1000, 48000, 4800},
+		{1234.5, 44100, 10000},
+		{60, 8000, 8000},
+		{3900, 8000, 8000},
+	}
+
+	for _, tt := range tests {
+		x := make([]float64, tt.length)
+		for i := range x {
+			x[i] = 0.3 + 0.5*math.Sin(2*math.Pi*tt.freq*float64(i)/tt.rate)
+		}
+
+		got := PeakFrequency(x, tt.rate)
+		binWidth := tt.rate / float64(tt.length)
+		if math.Abs(got-tt.freq) > binWidth/10 {
+			t.Errorf("%g Hz: wrong peak. got=%g (bins are %g Hz)", tt.freq, got, binWidth)
+		}
+	}
+
+	if got := PeakFrequency(make([]float64, 100), 8000); got != 0 {
+		t.Errorf("peak frequency of silence is %g", got)
+	}
+}
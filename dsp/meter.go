@@ -0,0 +1,201 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+	"wavy/audio"
+)
+
+// RMS returns the root mean square of all samples of b, over all channels.
+// It is 0 for an empty buffer.
+func RMS(b *audio.Buffer) float64 {
+	if len(b.Samples) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, s := range b.Samples {
+		sum += float64(s) * float64(s)
+	}
+
+	return math.Sqrt(sum / float64(len(b.Samples)))
+}
+
+// CrestFactor returns the ratio of the peak to the RMS level of b: √2 for a
+// sine and 1 for a square wave. It is 0 for silence.
+func CrestFactor(b *audio.Buffer) float64 {
+	rms := RMS(b)
+	if rms == 0 {
+		return 0
+	}
+	return Peak(b) / rms
+}
+
+// truePeakOversampling is the factor by which TruePeak oversamples audio
+// below 96 kHz, as ITU-R BS.1770-4 recommends for 48 kHz.
+const truePeakOversampling = 4
+
+// TruePeak estimates the peak of the continuous signal that b represents,
+// which can be higher than the largest sample when the peak falls between
+// samples. It oversamples audio below 96 kHz by a factor of four with a
+// band-limited interpolator and returns the largest absolute sample of the
+// result, or of b itself if that is larger.
+func TruePeak(b *audio.Buffer) float64 {
+	if b.SampleRate <= 0 || b.SampleRate >= 96000 {
+		return Peak(b)
+	}
+
+	oversampled, err := Resample(b, b.SampleRate*truePeakOversampling)
+	if err != nil {
+		return Peak(b)
+	}
+
+	return math.Max(Peak(b), Peak(oversampled))
+}
+
+// K-weighting filter parameters: a high shelf that models the acoustic
+// effect of the head, followed by a highpass (the "RLB" curve). ITU-R
+// BS.1770 only gives coefficients for 48 kHz; these are the analog
+// parameters they were derived from, which give the same coefficients at
+// 48 kHz through the bilinear transform and extend them to other rates.
+const (
+	kShelfFreq   = 1681.974450955533
+	kShelfQ      = 0.7071752369554196
+	kShelfGainDB = 3.999843853973347
+	kShelfBand   = 0.4996667741545416
+
+	kHighPassFreq = 38.13547087602444
+	kHighPassQ    = 0.5003270373238773
+)
+
+// Gating parameters from ITU-R BS.1770-4.
+const (
+	lufsBlockSeconds = 0.4
+	lufsStepSeconds  = 0.1 // blocks overlap by 75%
+	lufsAbsoluteGate = -70.0
+	lufsRelativeGate = -10.0
+)
+
+// KWeighting returns the two filters of the K-weighting curve for audio at
+// sampleRate, to be applied in order. The shelf differs slightly from the
+// cookbook HighShelf, so it is designed here, and the highpass keeps the
+// standard's unnormalized numerator of 1, -2, 1.
+func KWeighting(sampleRate float64) ([]Biquad, error) {
+	if !(sampleRate > 2*kShelfFreq) {
+		return nil, fmt.Errorf("cannot measure loudness at a sample rate of %g Hz", sampleRate)
+	}
+
+	k := math.Tan(math.Pi * kShelfFreq / sampleRate)
+	vh := math.Pow(10, kShelfGainDB/20)
+	vb := math.Pow(vh, kShelfBand)
+	a0 := 1 + k/kShelfQ + k*k
+	shelf := Biquad{
+		B0: (vh + vb*k/kShelfQ + k*k) / a0,
+		B1: 2 * (k*k - vh) / a0,
+		B2: (vh - vb*k/kShelfQ + k*k) / a0,
+		A1: 2 * (k*k - 1) / a0,
+		A2: (1 - k/kShelfQ + k*k) / a0,
+	}
+
+	k = math.Tan(math.Pi * kHighPassFreq / sampleRate)
+	a0 = 1 + k/kHighPassQ + k*k
+	highpass := Biquad{
+		B0: 1,
+		B1: -2,
+		B2: 1,
+		A1: 2 * (k*k - 1) / a0,
+		A2: (1 - k/kHighPassQ + k*k) / a0,
+	}
+
+	return []Biquad{shelf, highpass}, nil
+}
+
+// IntegratedLoudness measures the loudness of b in LUFS as defined by ITU-R
+// BS.1770-4: each channel is K-weighted, the mean square is taken over
+// 400 ms blocks that overlap by 75%, and the loudness is the average of the
+// blocks that pass an absolute gate at -70 LUFS and a relative gate 10 LU
+// below the loudness of the blocks that passed the first gate.
+//
+// Channels are weighted equally, except for six-channel (5.1) audio in the
+// order L, R, C, LFE, Ls, Rs, where the LFE channel is left out and the
+// surround channels are weighted by 1.41. Audio shorter than one block, or
+// too quiet to pass the gates, measures negative infinity.
+func IntegratedLoudness(b *audio.Buffer) (float64, error) {
+	filters, err := KWeighting(float64(b.SampleRate))
+	if err != nil {
+		return 0, err
+	}
+
+	weighted := b
+	for _, f := range filters {
+		weighted = f.Process(weighted)
+	}
+
+	weights := make([]float64, b.Channels)
+	for c := range weights {
+		weights[c] = 1
+	}
+	if b.Channels == 6 {
+		weights[3] = 0
+		weights[4], weights[5] = 1.41, 1.41
+	}
+
+	blockSize := int(math.Round(lufsBlockSeconds * float64(b.SampleRate)))
+	step := int(math.Round(lufsStepSeconds * float64(b.SampleRate)))
+
+	// the weighted sum of the channels' mean squares of each block
+	var powers []float64
+	for start := 0; start+blockSize <= weighted.Frames(); start += step {
+		power := 0.0
+		for c, weight := range weights {
+			if weight == 0 {
+				continue
+			}
+
+			sum := 0.0
+			for i := start; i < start+blockSize; i++ {
+				s := float64(weighted.Samples[i*b.Channels+c])
+				sum += s * s
+			}
+			power += weight * sum / float64(blockSize)
+		}
+		powers = append(powers, power)
+	}
+
+	gated := gateBlocks(powers, lufsAbsoluteGate)
+	if len(gated) == 0 {
+		return math.Inf(-1), nil
+	}
+
+	relative := loudness(mean(gated)) + lufsRelativeGate
+	gated = gateBlocks(gated, relative)
+	if len(gated) == 0 {
+		return math.Inf(-1), nil
+	}
+
+	return loudness(mean(gated)), nil
+}
+
+// loudness converts a weighted mean square to LUFS.
+func loudness(power float64) float64 {
+	return -0.691 + 10*math.Log10(power)
+}
+
+// gateBlocks returns the block powers whose loudness is above gate.
+func gateBlocks(powers []float64, gate float64) []float64 {
+	var kept []float64
+	for _, p := range powers {
+		if loudness(p) > gate {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,142 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+	"wavy/audio"
+)
+
+// referenceSine is the reference signal for the meters: a 1 kHz sine with
+// a peak of -20 dBFS, at 48 kHz.
+func referenceSine(seconds float64, channels int) *audio.Buffer {
+	b := sineBuffer(1000, 48000, int(seconds*48000), channels)
+	return scale(b, DBToGain(-20)/0.5)
+}
+
+func TestLevelMeters(t *testing.T) {
+	mono := referenceSine(5, 1)
+	stereo := referenceSine(5, 2)
+
+	tests := []struct {
+		name       string
+		measured   float64
+		expectedDB float64
+	}{
+		{"peak", Peak(mono), -20},
+		{"rms", RMS(mono), -20 - GainToDB(math.Sqrt2)},
+		{"crest factor", CrestFactor(mono), GainToDB(math.Sqrt2)},
+		{"true peak", TruePeak(mono), -20},
+		{"stereo rms", RMS(stereo), -20 - GainToDB(math.Sqrt2)},
+	}
+
+	for _, tt := range tests {
+		if db := GainToDB(tt.measured); math.Abs(db-tt.expectedDB) > 0.01 {
+			t.Errorf("%s: want=%.3f dB, got=%.3f dB", tt.name, tt.expectedDB, db)
+		}
+	}
+}
+
+func TestIntegratedLoudness(t *testing.T) {
+	quiet := scale(referenceSine(10, 1), DBToGain(-20))
+	silence := &audio.Buffer{Samples: make([]float32, 48000*10), SampleRate: 48000, Channels: 1}
+	surround := referenceSine(5, 6)
+	for i := 3; i < len(surround.Samples); i += 6 {
+		surround.Samples[i] = 1 // the LFE channel does not count
+	}
+
+	tests := []struct {
+		name     string
+		input    *audio.Buffer
+		expected float64
+	}{
+		// the K-weighting curve has a gain of about +0.69 dB at 1 kHz, which
+		// the -0.691 in the definition of LUFS cancels, so a mono sine reads
+		// its RMS level
+		{"mono", referenceSine(5, 1), -23.01},
+		// the power of the channels adds up
+		{"stereo", referenceSine(5, 2), -20.00},
+		{"44.1 kHz", scale(sineBuffer(1000, 44100, 44100*5, 1), DBToGain(-20)/0.5), -23.01},
+		// L, R and C at 1 and Ls and Rs at 1.41: 10*log10(3 + 2*1.41) higher
+		{"5.1", surround, -23.01 + 10*math.Log10(5.82)},
+		// silence is below the absolute gate and does not lower the reading,
+		// but the three blocks that are 75%, 50% and 25% tone pass both
+		// gates: 17 full blocks and 1.5 blocks' worth of tone in 20 blocks
+		{"tone then silence", concatBuffers(referenceSine(2, 1), silence), -23.01 + 10*math.Log10(18.5/20)},
+		// audio 20 LU quieter is below the relative gate, and the blocks
+		// across the change pass it: 97 loud blocks and 3 partly loud ones,
+		// which have 1.5 blocks' worth of the loud tone and 1.5 of the quiet
+		{"tone then quiet tone", concatBuffers(referenceSine(10, 1), quiet), -23.01 + 10*math.Log10((98.5+0.015)/100)},
+		{"silence", silence, math.Inf(-1)},
+		{"shorter than a block", referenceSine(0.3, 1), math.Inf(-1)},
+	}
+
+	for _, tt := range tests {
+		got, err := IntegratedLoudness(tt.input)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+
+		if math.IsInf(tt.expected, -1) {
+			if !math.IsInf(got, -1) {
+				t.Errorf("%s: want=-Inf, got=%.3f LUFS", tt.name, got)
+			}
+			continue
+		}
+
+		if math.Abs(got-tt.expected) > 0.05 {
+			t.Errorf("%s: want=%.3f LUFS, got=%.3f LUFS", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestKWeightingMatchesStandard(t *testing.T) {
+	filters, err := KWeighting(48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// coefficients for 48 kHz from ITU-R BS.1770-4, tables 1 and 2
+	expected := []Biquad{
+		{B0: 1.53512485958697, B1: -2.69169618940638, B2: 1.19839281085285,
+			A1: -1.69065929318241, A2: 0.73248077421585},
+		{B0: 1, B1: -2, B2: 1, A1: -1.99004745483398, A2: 0.99007225036621},
+	}
+
+	for i, want := range expected {
+		got := filters[i]
+		coefficients := [][2]float64{
+			{want.B0, got.B0}, {want.B1, got.B1}, {want.B2, got.B2},
+			{want.A1, got.A1}, {want.A2, got.A2},
+		}
+		for j, c := range coefficients {
+			if math.Abs(c[0]-c[1]) > 1e-8 {
+				t.Errorf("filter %d: coefficient %d wrong. want=%.14f, got=%.14f", i, j, c[0], c[1])
+			}
+		}
+	}
+}
+
+func TestTruePeakBetweenSamples(t *testing.T) {
+	// a sine at a quarter of the sample rate, shifted by 45 degrees, is
+	// only ever sampled at ±0.707 of its amplitude
+	b := &audio.Buffer{Samples: make([]float32, 4800), SampleRate: 48000, Channels: 1}
+	for i := range b.Samples {
+		b.Samples[i] = float32(0.5 * math.Sin(math.Pi/2*float64(i)+math.Pi/4))
+	}
+
+	if sample := GainToDB(Peak(b)); math.Abs(sample-GainToDB(0.5)+3.01) > 0.01 {
+		t.Errorf("wrong sample peak: %.3f dB", sample)
+	}
+	// fading avoids the overshoot of the band-limited signal at an abrupt
+	// start or end
+	b = must(FadeIn(b, 0.01))
+	b = must(FadeOut(b, 0.01))
+
+	if got := GainToDB(TruePeak(b)); math.Abs(got-GainToDB(0.5)) > 0.01 {
+		t.Errorf("wrong true peak. want=%.3f dB, got=%.3f dB", GainToDB(0.5), got)
+	}
+}
+
+func concatBuffers(a, b *audio.Buffer) *audio.Buffer {
+	return withSamples(a, append(append([]float32{}, a.Samples...), b.Samples...))
+}
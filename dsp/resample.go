@@ -0,0 +1,86 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+	"wavy/audio"
+)
+
+// resampleZeroCrossings is the number of zero crossings of the sinc kernel
+// on each side of the output sample. More gives a steeper anti-aliasing
+// filter at the cost of speed.
+const resampleZeroCrossings = 16
+
+// Resample converts b to a new sample rate using windowed sinc
+// interpolation. When lowering the rate the kernel is widened so that
+// frequencies above the new Nyquist frequency are filtered out instead of
+// aliasing. The length in seconds is kept, rounded to the nearest frame.
+func Resample(b *audio.Buffer, rate int) (*audio.Buffer, error) {
+	if rate <= 0 {
+		return nil, fmt.Errorf("sample rate must be positive, got %d", rate)
+	}
+	if b.SampleRate <= 0 {
+		return nil, fmt.Errorf("cannot resample audio with sample rate %d", b.SampleRate)
+	}
+
+	out := &audio.Buffer{
+		SampleRate: rate,
+		Channels:   b.Channels,
+		BitDepth:   b.BitDepth,
+	}
+	if rate == b.SampleRate {
+		out.Samples = append([]float32{}, b.Samples...)
+		return out, nil
+	}
+
+	ratio := float64(b.SampleRate) / float64(rate)
+	cutoff := math.Min(1, 1/ratio)
+	halfWidth := float64(resampleZeroCrossings) / cutoff
+
+	inFrames := b.Frames()
+	frames := math.Round(float64(inFrames) / ratio)
+	if frames*float64(b.Channels) > audio.MaxSamples {
+		return nil, fmt.Errorf("resampling to %dHz gives too many samples", rate)
+	}
+	outFrames := int(frames)
+	out.Samples = make([]float32, outFrames*b.Channels)
+
+	sums := make([]float64, b.Channels)
+	for n := 0; n < outFrames; n++ {
+		t := float64(n) * ratio
+
+		first := max(0, int(math.Ceil(t-halfWidth)))
+		last := min(inFrames-1, int(math.Floor(t+halfWidth)))
+
+		clear(sums)
+		for k := first; k <= last; k++ {
+			x := t - float64(k)
+			w := cutoff * sinc(cutoff*x) * blackman(x/halfWidth)
+			for c := range sums {
+				sums[c] += w * float64(b.Samples[k*b.Channels+c])
+			}
+		}
+
+		for c, sum := range sums {
+			out.Samples[n*b.Channels+c] = float32(sum)
+		}
+	}
+
+	return out, nil
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman is the Blackman window centered on 0, for -1 <= x <= 1.
+func blackman(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+	phase := math.Pi * (x + 1)
+	return 0.42 - 0.5*math.Cos(phase) + 0.08*math.Cos(2*phase)
+}
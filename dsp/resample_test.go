@@ -0,0 +1,76 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+	"wavy/audio"
+)
+
+func sineBuffer(freq float64, rate, frames, channels int) *audio.Buffer {
+	samples := make([]float32, frames*channels)
+	for i := range samples {
+		frame := i / channels
+		samples[i] = float32(0.5 * math.Sin(2*math.Pi*freq*float64(frame)/float64(rate)))
+	}
+
+	return &audio.Buffer{Samples: samples, SampleRate: rate, Channels: channels, BitDepth: 16}
+}
+
+func TestResample(t *testing.T) {
+	tests := []struct {
+		freq     float64
+		from, to int
+	}{
+		{440, 44100, 48000},
+		{440, 48000, 44100},
+		{1000, 8000, 22050},
+		{3000, 44100, 16000},
+	}
+
+	for _, tt := range tests {
+		in := sineBuffer(tt.freq, tt.from, tt.from/2, 2)
+		out := must(Resample(in, tt.to))
+
+		if out.SampleRate != tt.to || out.Channels != 2 || out.BitDepth != 16 {
+			t.Errorf("%d->%d: wrong format. got=%dHz %dch %d-bit", tt.from, tt.to,
+				out.SampleRate, out.Channels, out.BitDepth)
+		}
+		if out.Frames() != tt.to/2 {
+			t.Errorf("%d->%d: wrong length. want=%d, got=%d", tt.from, tt.to, tt.to/2, out.Frames())
+		}
+
+		// away from the edges, where the kernel runs out of input, the
+		// result is the same sine sampled at the new rate
+		want := sineBuffer(tt.freq, tt.to, out.Frames(), 2)
+		worst := 0.0
+		for i := out.Frames() / 4 * 2; i < out.Frames()*3/4*2; i++ {
+			worst = math.Max(worst, math.Abs(float64(out.Samples[i]-want.Samples[i])))
+		}
+		if worst > 1e-3 {
+			t.Errorf("%d->%d: max error %g", tt.from, tt.to, worst)
+		}
+	}
+}
+
+func TestResampleFiltersAliases(t *testing.T) {
+	// 15 kHz cannot be represented at 16 kHz and has to be removed rather
+	// than fold down to 1 kHz
+	in := sineBuffer(15000, 48000, 48000, 1)
+	out := must(Resample(in, 16000))
+
+	middle := out.Samples[4000:12000]
+	if peak := Peak(&audio.Buffer{Samples: middle, Channels: 1}); peak > 0.005 {
+		t.Errorf("alias not filtered out. peak=%g", peak)
+	}
+}
+
+func TestResampleSameRate(t *testing.T) {
+	in := sineBuffer(440, 8000, 100, 1)
+	out := must(Resample(in, 8000))
+
+	testSamples(t, "same rate", in.Samples, out.Samples)
+	out.Samples[0] = 1
+	if in.Samples[0] == 1 {
+		t.Errorf("resampling to the same rate did not copy the samples")
+	}
+}
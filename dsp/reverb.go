@@ -0,0 +1,121 @@
+package dsp
+
+import (
+	"fmt"
+	"wavy/audio"
+)
+
+// Freeverb tuning by Jezar at Dreampoint. The delay lengths are in samples
+// at 44.1 kHz and are scaled to the rate of the audio being processed.
+var (
+	freeverbCombs     = []int{1116, 1188, 1277, 1356, 1422, 1491, 1557, 1617}
+	freeverbAllpasses = []int{556, 441, 341, 225}
+)
+
+const (
+	freeverbRate       = 44100.0
+	freeverbSpread     = 23 // extra delay per channel, which decorrelates them
+	freeverbInputGain  = 0.015
+	freeverbWetGain    = 3
+	freeverbRoomScale  = 0.28
+	freeverbRoomOffset = 0.7
+	freeverbDampScale  = 0.4
+	freeverbAllpassFB  = 0.5
+)
+
+// Reverb simulates a room with Jezar's Freeverb: the channels are summed
+// and fed through eight parallel lowpass-feedback comb filters and four
+// allpass filters in series, with slightly longer delays for each output
+// channel. roomSize sets how long the reverb rings and damping how quickly
+// high frequencies die away, both between 0 and 1, and mix sets the balance
+// between the dry input (0) and the reverberated signal (1).
+func Reverb(b *audio.Buffer, roomSize, damping, mix float64) (*audio.Buffer, error) {
+	if !(roomSize >= 0 && roomSize <= 1) {
+		return nil, fmt.Errorf("room size must be between 0 and 1, got %g", roomSize)
+	}
+	if !(damping >= 0 && damping <= 1) {
+		return nil, fmt.Errorf("damping must be between 0 and 1, got %g", damping)
+	}
+	if err := checkMix(mix); err != nil {
+		return nil, err
+	}
+	if b.SampleRate <= 0 {
+		return nil, fmt.Errorf("cannot reverberate audio with sample rate %d", b.SampleRate)
+	}
+
+	ch := b.Channels
+	frames := b.Frames()
+	feedback := roomSize*freeverbRoomScale + freeverbRoomOffset
+	damp := damping * freeverbDampScale
+
+	input := make([]float64, frames)
+	for n := range input {
+		sum := 0.0
+		for c := 0; c < ch; c++ {
+			sum += float64(b.Samples[n*ch+c])
+		}
+		input[n] = sum * freeverbInputGain
+	}
+
+	samples := make([]float32, len(b.Samples))
+	for c := 0; c < ch; c++ {
+		combs := make([]comb, len(freeverbCombs))
+		for i, size := range freeverbCombs {
+			combs[i] = comb{buffer: make([]float64, scaleTuning(b, size+c*freeverbSpread))}
+		}
+		allpasses := make([]allpass, len(freeverbAllpasses))
+		for i, size := range freeverbAllpasses {
+			allpasses[i] = allpass{buffer: make([]float64, scaleTuning(b, size+c*freeverbSpread))}
+		}
+
+		for n, x := range input {
+			wet := 0.0
+			for i := range combs {
+				wet += combs[i].process(x, feedback, damp)
+			}
+			for i := range allpasses {
+				wet = allpasses[i].process(wet)
+			}
+
+			i := n*ch + c
+			samples[i] = float32((1-mix)*float64(b.Samples[i]) + mix*wet*freeverbWetGain)
+		}
+	}
+
+	return withSamples(b, samples), nil
+}
+
+// scaleTuning converts a delay in samples at 44.1 kHz to the rate of b.
+func scaleTuning(b *audio.Buffer, size int) int {
+	return max(1, int(float64(size)*float64(b.SampleRate)/freeverbRate))
+}
+
+// comb is a feedback comb filter with a one-pole lowpass in its feedback
+// path.
+type comb struct {
+	buffer []float64
+	pos    int
+	store  float64 // state of the lowpass
+}
+
+func (f *comb) process(x, feedback, damp float64) float64 {
+	y := f.buffer[f.pos]
+	f.store = y*(1-damp) + f.store*damp
+	f.buffer[f.pos] = x + f.store*feedback
+	f.pos = (f.pos + 1) % len(f.buffer)
+	return y
+}
+
+// allpass is Freeverb's allpass filter, which smears the comb output in
+// time without colouring its spectrum much.
+type allpass struct {
+	buffer []float64
+	pos    int
+}
+
+func (f *allpass) process(x float64) float64 {
+	delayed := f.buffer[f.pos]
+	f.buffer[f.pos] = x + delayed*freeverbAllpassFB
+	f.pos = (f.pos + 1) % len(f.buffer)
+	return delayed - x
+}
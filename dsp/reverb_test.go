@@ -0,0 +1,78 @@
+package dsp
+
+import (
+	"testing"
+	"wavy/audio"
+)
+
+func TestReverb(t *testing.T) {
+	impulse := &audio.Buffer{
+		Samples:    make([]float32, 2*44100),
+		SampleRate: 44100, Channels: 2, BitDepth: 16,
+	}
+	impulse.Samples[0], impulse.Samples[1] = 1, 1
+
+	dry := must(Reverb(impulse, 0.5, 0.5, 0))
+	testSamples(t, "dry", impulse.Samples, dry.Samples)
+
+	wet := must(Reverb(impulse, 0.5, 0.5, 1))
+	again := must(Reverb(impulse, 0.5, 0.5, 1))
+	testSamples(t, "deterministic", wet.Samples, again.Samples)
+
+	// nothing comes out before the shortest comb delay
+	first := -1
+	for i, s := range wet.Samples {
+		if s != 0 {
+			first = i / 2
+			break
+		}
+	}
+	if first != 1116 {
+		t.Errorf("reverb starts at the wrong frame. want=%d, got=%d", 1116, first)
+	}
+
+	// the channels have different delays, so they are decorrelated
+	if wet.Samples[2*5000] == wet.Samples[2*5000+1] {
+		t.Errorf("reverb channels should differ")
+	}
+
+	// a larger room rings longer
+	tail := func(b *audio.Buffer) float64 {
+		return RMS(&audio.Buffer{Samples: b.Samples[len(b.Samples)/2:], Channels: 2})
+	}
+	small := must(Reverb(impulse, 0.1, 0.5, 1))
+	large := must(Reverb(impulse, 0.9, 0.5, 1))
+	if tail(large) <= tail(small) {
+		t.Errorf("large room tail %g should be louder than small room tail %g", tail(large), tail(small))
+	}
+
+	// damping takes away high frequencies
+	bright := must(Reverb(impulse, 0.5, 0, 1))
+	dark := must(Reverb(impulse, 0.5, 1, 1))
+	highs := func(b *audio.Buffer) float64 {
+		f, _ := HighPass(44100, 5000, Butterworth)
+		return RMS(f.Process(b))
+	}
+	if highs(dark) >= highs(bright) {
+		t.Errorf("damped reverb should have fewer highs. got=%g, undamped=%g", highs(dark), highs(bright))
+	}
+}
+
+func TestReverbErrors(t *testing.T) {
+	b := &audio.Buffer{Samples: []float32{0}, SampleRate: 8000, Channels: 1}
+
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{errorOf(Reverb(b, 1.5, 0.5, 0.3)), "room size must be between 0 and 1, got 1.5"},
+		{errorOf(Reverb(b, 0.5, -1, 0.3)), "damping must be between 0 and 1, got -1"},
+		{errorOf(Reverb(b, 0.5, 0.5, -0.3)), "mix must be between 0 and 1, got -0.3"},
+	}
+
+	for _, tt := range tests {
+		if tt.err == nil || tt.err.Error() != tt.expected {
+			t.Errorf("wrong error. want=%q, got=%v", tt.expected, tt.err)
+		}
+	}
+}
@@ -0,0 +1,459 @@
+// Package evaluator runs programs by walking the AST. It is a reference
+// implementation of the language the compiler and VM implement, and is
+// used to check them against each other: for any program both accept, the
+// results and output should be the same.
+//
+// The one known difference is in closures. The VM copies free variables into
+// a closure when it is created, while the evaluator keeps a reference to the
+// enclosing environment, so a local redefined after a closure captured it is
+// seen by the evaluator but not by the VM.
+package evaluator
+
+import (
+	"fmt"
+	"sort"
+	"wavy/ast"
+	"wavy/object"
+	"wavy/token"
+)
+
+// MaxDepth limits the depth of nested calls, matching the VM's frame limit.
+const MaxDepth = 1024
+
+var (
+	NULL  = &object.Null{}
+	TRUE  = &object.Boolean{Value: true}
+	FALSE = &object.Boolean{Value: false}
+)
+
+// Eval evaluates node in env. Runtime errors are returned as *object.Error;
+// errors returned by builtins are ordinary values, as in the VM. Statements
+// that produce no value, such as let, evaluate to nil.
+func Eval(node ast.Node, env *object.Environment) object.Object {
+	e := &evaluator{}
+
+	result := e.eval(node, env)
+	switch result := result.(type) {
+	case *runtimeError:
+		return result.Error
+	case *object.ReturnValue:
+		return result.Value
+	case *loopSignal:
+		return newError(result.pos, "%s outside loop", result.Inspect()).Error
+	}
+
+	return result
+}
+
+type evaluator struct {
+	depth int
+}
+
+// runtimeError wraps the errors raised by the evaluator itself, so that they
+// can be told apart from *object.Error values returned by builtins.
+type runtimeError struct {
+	*object.Error
+}
+
+// loopSignal is returned by break and continue statements and unwinds the
+// evaluation of statements up to the innermost loop.
+type loopSignal struct {
+	isBreak bool
+	pos     token.Position
+}
+
+func (ls *loopSignal) Type() object.ObjectType { return "LOOP_SIGNAL" }
+func (ls *loopSignal) Inspect() string {
+	if ls.isBreak {
+		return "break"
+	}
+	return "continue"
+}
+
+func newError(pos token.Position, format string, a ...interface{}) *runtimeError {
+	msg := fmt.Sprintf(format, a...)
+	if pos.IsValid() {
+		msg = fmt.Sprintf("%s at %s", msg, pos)
+	}
+	return &runtimeError{&object.Error{Message: msg}}
+}
+
+// unwinds reports whether obj stops evaluation and has to be passed up to
+// the enclosing function, loop or program.
+func unwinds(obj object.Object) bool {
+	switch obj.(type) {
+	case *runtimeError, *object.ReturnValue, *loopSignal:
+		return true
+	}
+	return false
+}
+
+func (e *evaluator) eval(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+
+	// Statements
+	case *ast.Program:
+		return e.evalStatements(node.Statements, env)
+
+	case *ast.BlockStatement:
+		return e.evalStatements(node.Statements, env)
+
+	case *ast.ExpressionStatement:
+		return e.eval(node.Expression, env)
+
+	case *ast.LetStatement:
+		val := e.eval(node.Value, env)
+		if unwinds(val) {
+			return val
+		}
+		env.Set(node.Name.Value, val)
+		return nil
+
+	case *ast.ReturnStatement:
+		val := e.eval(node.ReturnValue, env)
+		if unwinds(val) {
+			return val
+		}
+		return &object.ReturnValue{Value: val}
+
+	case *ast.WhileStatement:
+		return e.evalWhileStatement(node, env)
+
+	case *ast.ForStatement:
+		return e.evalForStatement(node, env)
+
+	case *ast.BreakStatement:
+		return &loopSignal{isBreak: true, pos: node.Pos()}
+
+	case *ast.ContinueStatement:
+		return &loopSignal{isBreak: false, pos: node.Pos()}
+
+	// Expressions
+	case *ast.IntegerLiteral:
+		return &object.Integer{Value: node.Value}
+
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
+
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(node.Value)
+
+	case *ast.Identifier:
+		return e.evalIdentifier(node, env)
+
+	case *ast.PrefixExpression:
+		right := e.eval(node.Right, env)
+		if unwinds(right) {
+			return right
+		}
+		return evalPrefixExpression(node, right)
+
+	case *ast.InfixExpression:
+		left := e.eval(node.Left, env)
+		if unwinds(left) {
+			return left
+		}
+
+		// && and || only evaluate the right operand when it decides the result
+		if node.Operator == "&&" && !isTruthy(left) {
+			return FALSE
+		}
+		if node.Operator == "||" && isTruthy(left) {
+			return TRUE
+		}
+
+		right := e.eval(node.Right, env)
+		if unwinds(right) {
+			return right
+		}
+
+		return evalInfixExpression(node, left, right)
+
+	case *ast.IfExpression:
+		return e.evalIfExpression(node, env)
+
+	case *ast.FunctionLiteral:
+		return &object.Function{Parameters: node.Parameters, Body: node.Body, Env: env}
+
+	case *ast.CallExpression:
+		function := e.eval(node.Function, env)
+		if unwinds(function) {
+			return function
+		}
+
+		args := e.evalExpressions(node.Arguments, env)
+		if len(args) == 1 && unwinds(args[0]) {
+			return args[0]
+		}
+
+		return e.applyFunction(node, function, args)
+
+	case *ast.ArrayLiteral:
+		elements := e.evalExpressions(node.Elements, env)
+		if len(elements) == 1 && unwinds(elements[0]) {
+			return elements[0]
+		}
+		return &object.Array{Elements: elements}
+
+	case *ast.IndexExpression:
+		left := e.eval(node.Left, env)
+		if unwinds(left) {
+			return left
+		}
+
+		index := e.eval(node.Index, env)
+		if unwinds(index) {
+			return index
+		}
+
+		return evalIndexExpression(node, left, index)
+
+	case *ast.SliceExpression:
+		return e.evalSliceExpression(node, env)
+
+	case *ast.HashLiteral:
+		return e.evalHashLiteral(node, env)
+	}
+
+	return newError(node.Pos(), "cannot evaluate %T", node)
+}
+
+// evalStatements returns the value of the last statement, or the first
+// error, return value or loop signal.
+func (e *evaluator) evalStatements(stmts []ast.Statement, env *object.Environment) object.Object {
+	var result object.Object
+
+	for _, statement := range stmts {
+		result = e.eval(statement, env)
+		if unwinds(result) {
+			return result
+		}
+	}
+
+	return result
+}
+
+func (e *evaluator) evalWhileStatement(node *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := e.eval(node.Condition, env)
+		if unwinds(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return nil
+		}
+
+		if result, done := e.evalLoopBody(node.Body, env); done {
+			return result
+		}
+	}
+}
+
+func (e *evaluator) evalForStatement(node *ast.ForStatement, env *object.Environment) object.Object {
+	iterable := e.eval(node.Iterable, env)
+	if unwinds(iterable) {
+		return iterable
+	}
+
+	iterator, ok := object.NewIterator(iterable)
+	if !ok {
+		return newError(node.Pos(), "cannot iterate over %s", iterable.Type())
+	}
+
+	for {
+		value, ok := iterator.Next()
+		if !ok {
+			return nil
+		}
+
+		env.Set(node.Variable.Value, value)
+
+		if result, done := e.evalLoopBody(node.Body, env); done {
+			return result
+		}
+	}
+}
+
+// evalLoopBody runs one iteration. It reports done when the loop should stop,
+// with the value that the loop statement evaluates to.
+func (e *evaluator) evalLoopBody(body *ast.BlockStatement, env *object.Environment) (object.Object, bool) {
+	result := e.eval(body, env)
+
+	if signal, ok := result.(*loopSignal); ok {
+		return nil, signal.isBreak
+	}
+	if unwinds(result) {
+		return result, true
+	}
+
+	return nil, false
+}
+
+func (e *evaluator) evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+	if val, ok := env.Get(node.Value); ok {
+		return val
+	}
+
+	if builtin := object.GetBuiltinByName(node.Value); builtin != nil {
+		return builtin
+	}
+
+	return newError(node.Pos(), "undefined variable %s", node.Value)
+}
+
+// blockValue is the value of an if branch or function body: the value of its
+// last statement if that is an expression, and NULL otherwise.
+func blockValue(block *ast.BlockStatement, result object.Object) object.Object {
+	n := len(block.Statements)
+	if n == 0 {
+		return NULL
+	}
+	if _, ok := block.Statements[n-1].(*ast.ExpressionStatement); !ok {
+		return NULL
+	}
+	if result == nil {
+		return NULL
+	}
+	return result
+}
+
+func (e *evaluator) evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
+	condition := e.eval(ie.Condition, env)
+	if unwinds(condition) {
+		return condition
+	}
+
+	block := ie.Alternative
+	if isTruthy(condition) {
+		block = ie.Consequence
+	}
+	if block == nil {
+		return NULL
+	}
+
+	result := e.eval(block, env)
+	if unwinds(result) {
+		return result
+	}
+
+	return blockValue(block, result)
+}
+
+func (e *evaluator) evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+	var result []object.Object
+
+	for _, exp := range exps {
+		evaluated := e.eval(exp, env)
+		if unwinds(evaluated) {
+			return []object.Object{evaluated}
+		}
+		result = append(result, evaluated)
+	}
+
+	return result
+}
+
+func (e *evaluator) evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
+	left := e.eval(node.Left, env)
+	if unwinds(left) {
+		return left
+	}
+
+	bounds := []object.Object{NULL, NULL}
+	for i, bound := range []ast.Expression{node.Start, node.End} {
+		if bound == nil {
+			continue
+		}
+
+		bounds[i] = e.eval(bound, env)
+		if unwinds(bounds[i]) {
+			return bounds[i]
+		}
+	}
+
+	result, err := object.Slice(left, bounds[0], bounds[1])
+	if err != nil {
+		return newError(node.Pos(), "%s", err)
+	}
+
+	return result
+}
+
+func (e *evaluator) evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	// evaluate in the same order as the compiler emits the pairs
+	keys := []ast.Expression{}
+	for k := range node.Pairs {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	pairs := make(map[object.HashKey]object.HashPair)
+
+	for _, keyNode := range keys {
+		key := e.eval(keyNode, env)
+		if unwinds(key) {
+			return key
+		}
+
+		value := e.eval(node.Pairs[keyNode], env)
+		if unwinds(value) {
+			return value
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError(node.Pos(), "unusable as hash key: %s", key.Type())
+		}
+
+		pairs[hashKey.HashKey()] = object.HashPair{Key: key, Value: value}
+	}
+
+	return &object.Hash{Pairs: pairs}
+}
+
+func (e *evaluator) applyFunction(node *ast.CallExpression, fn object.Object, args []object.Object) object.Object {
+	switch fn := fn.(type) {
+	case *object.Function:
+		if len(args) != len(fn.Parameters) {
+			return newError(node.Pos(), "wrong number of arguments: want=%d, got=%d",
+				len(fn.Parameters), len(args))
+		}
+		if e.depth >= MaxDepth-1 {
+			return newError(node.Pos(), "call stack overflow")
+		}
+
+		env := object.NewEnclosedEnvironment(fn.Env)
+		for i, param := range fn.Parameters {
+			env.Set(param.Value, args[i])
+		}
+
+		e.depth++
+		result := e.eval(fn.Body, env)
+		e.depth--
+
+		switch result := result.(type) {
+		case *object.ReturnValue:
+			return result.Value
+		case *runtimeError:
+			return result
+		case *loopSignal:
+			return newError(result.pos, "%s outside loop", result.Inspect())
+		}
+
+		return blockValue(fn.Body, result)
+
+	case *object.Builtin:
+		if result := fn.Fn(args...); result != nil {
+			return result
+		}
+		return NULL
+
+	default:
+		return newError(node.Pos(), "calling non-closure and non-builtin")
+	}
+}
@@ -0,0 +1,348 @@
+package evaluator
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"wavy/ast"
+	"wavy/compiler"
+	"wavy/lexer"
+	"wavy/object"
+	"wavy/parser"
+	"wavy/vm"
+)
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"5", 5},
+		{"-5 + 10 * 2", 15},
+		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"7 / 2.0", 3.5},
+		{"1 < 2", true},
+		{"1.5 > 2", false},
+		{"1 == 1.0", true},
+		{"!5", false},
+		{"!!true", true},
+		{"true == true", true},
+		{"true != false", true},
+		{`"wa" + "vy"`, "wavy"},
+		{"if (1 > 2) { 10 }", nil},
+		{"if (1 < 2) { 10 } else { 20 }", 10},
+		{"if (true) { let a = 1; }", nil},
+		{"let a = 5; let b = a * 2; b", 10},
+		{"let a = 1; let a = a + 1; a", 2},
+		{"let f = fn(x) { return x * 2; 100 }; f(4)", 8},
+		{"let f = fn(x) { if (x > 1) { return x; } 0 }; f(5)", 5},
+		{"let f = fn() { let x = 1; }; f()", nil},
+		{"let adder = fn(a) { fn(b) { a + b } }; adder(2)(3)", 5},
+		{"let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } }; fib(15)", 610},
+		{"[1, 2 * 2, 3][1]", 4},
+		{"[1, 2, 3][3]", nil},
+		{`{"one": 1, "two": 2}["two"]`, 2},
+		{`{"one": 1}["three"]`, nil},
+		{`len("wavy") + len([1, 2])`, 6},
+		{`let i = 0; while (i < 10) { let i = i + 1; } i`, 10},
+		{`let s = 0; for (x in [1, 2, 3, 4]) { if (x == 3) { continue; } let s = s + x; } s`, 7},
+		{`let s = 0; for (x in [1, 2, 3, 4]) { if (x == 3) { break; } let s = s + x; } s`, 3},
+		{`let f = fn() { for (x in [1, 2, 3]) { if (x == 2) { return x * 10; } } }; f()`, 20},
+		{`let s = ""; for (k in {"b": 1, "a": 2}) { let s = s + k; } s`, "ab"},
+		{`first([])`, nil},
+		{`"caf\u00e9"[3] + "\n"`, "é\n"},
+		{"7 % 3 + -7 % 3", 0},
+		{"7.5 % 2", 1.5},
+		{"1 <= 1 && 2 >= 3", false},
+		{"1 > 2 || 2 <= 2", true},
+		{"let a = []; len(a) > 0 && a[0] > 1", false},
+		{"true || 1 / 0", true},
+		{`len("naïve")`, 5},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		testObject(t, tt.input, tt.expected, evaluated)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5 + true", "unsupported types for binary operation: INTEGER BOOLEAN at line 1, position 3"},
+		{"-true", "unsupported type for negation: BOOLEAN at line 1, position 1"},
+		{`"a" - "b"`, "unknown string operator: - at line 1, position 5"},
+		{"true > false", "unknown operator: BOOLEAN > BOOLEAN at line 1, position 6"},
+		{"1 / 0", "division by zero at line 1, position 3"},
+		{"1 % 0", "modulo by zero at line 1, position 3"},
+		{"true && 1 / 0", "division by zero at line 1, position 11"},
+		{"foobar", "undefined variable foobar at line 1, position 1"},
+		{"let f = fn(x) {\n  x + \"a\"\n};\nf(1)", "unsupported types for binary operation: INTEGER STRING at line 2, position 5"},
+		{"fn(a) { a }()", "wrong number of arguments: want=1, got=0 at line 1, position 12"},
+		{"5()", "calling non-closure and non-builtin at line 1, position 2"},
+		{"[1][fn() {}]", "index operator not supported: ARRAY at line 1, position 4"},
+		{"{}[fn() {}]", "unusable as hash key: FUNCTION at line 1, position 3"},
+		{"for (x in 1) { x }", "cannot iterate over INTEGER at line 1, position 1"},
+		{"break;", "break outside loop at line 1, position 1"},
+		{"sine(1, 1, 8) + sine(1, 1, 4)", "mismatched sample rates: 8Hz and 4Hz at line 1, position 15"},
+		{"silence(1, 8) % 2", "unsupported types for binary operation: AUDIO INTEGER at line 1, position 15"},
+		{"silence(1, 8) / 0", "division by zero at line 1, position 15"},
+		{"[1, 2][1.0:]", "slice bounds must be INTEGER, got FLOAT at line 1, position 7"},
+		{"{1: 2}[1:]", "slice operator not supported: HASH at line 1, position 7"},
+		{"while (true) { fn() { continue; }() }", "continue outside loop at line 1, position 23"},
+		{"let f = fn(n) { f(n + 1) }; f(0)", "call stack overflow at line 1, position 18"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%q: no error object returned. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expected {
+			t.Errorf("%q: wrong error message. expected=%q, got=%q",
+				tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestBuiltinErrorsAreValues(t *testing.T) {
+	input := `let e = len(1); let x = 2; x`
+
+	testObject(t, input, 2, testEval(t, input))
+}
+
+// TestMatchesVM runs the sample programs and a set of inline programs through
+// both the evaluator and the compiler and VM, and compares what they print
+// and the value of the last expression.
+func TestMatchesVM(t *testing.T) {
+	// setup shared by the audio cases below
+	const (
+		noise    = `let noise = white_noise(0.05, 44100, 0.5, 9); `
+		wave     = `let wave = square(100, 0.01, 8000); `
+		tone     = `let tone = sine(997, 1, 44100, 0.25) + pink_noise(1, 44100, 0.01); `
+		saw      = `let saw = sawtooth(220, 0.2, 8000, 0.5); `
+		voice    = `let voice = sine(200, 0.5, 8000, 0.8) + white_noise(0.5, 8000, 0.01, 4); `
+		take     = `let beep = fade_out(sine(600, 0.2, 8000, 0.5), 0.05); let take = concat(silence(0.3, 8000), beep, silence(0.6, 8000), [beep, beep], 0.01); `
+		surround = `let surround = merge_channels([sine(200, 0.1, 8000), sine(300, 0.1, 8000), sine(400, 0.1, 8000), sine(50, 0.1, 8000), silence(0.05, 8000), white_noise(0.1, 8000, 0.1, 2)]); `
+	)
+
+	inputs := []string{
+		`let a = [1, 2.5, "x", true, {"k": [1]}]; a`,
+		`let total = 0; for (x in [1, 2, 3]) { let total = total + x * 1.5; } total`,
+		`let count = fn(n) { let i = 0; while (i < n) { let i = i + 1; if (i == 3) { break; } } i }; count(10)`,
+		`let apply = fn(f, x) { f(x) }; apply(fn(x) { x * x }, 7)`,
+		`let max = fn(a, b) { if (a > b) { a } else { b } }; [max(1, 2), max(3.5, 2), max(-1, -2)]`,
+		`let h = {1: "one", true: "yes", "k": "v"}; [h[1], h[true], h["k"], h[2]]`,
+		`if (false) { 1 }`,
+		`let f = fn() { while (true) { return 3; } }; f()`,
+		`puts("a", 1, [2]); 0`,
+		`let x = if (true) { }; x`,
+		`[1 <= 2, 2 >= 3, 9 % 4, 9.5 % 4, 1 && 0, 0 || if (false) { 1 }, false || "x"]`,
+		`let xs = [3, 0]; let i = 0; while (i < len(xs) && xs[i] > 0) { let i = i + 1; } i`,
+		`let s = 0; for (c in "abc") { let s = s + 1; } s`,
+		`let name = "Beyoncé\t🎵"; [len(name), name[6], name[8], name[9]]`,
+		`let tone = triangle(441, 0.01, 44100, 0.5); [tone, len(tone), tone[25], pink_noise(0.1, 8000, 1, 3)[100]]`,
+		`let xs = [1, 2, 3, 4]; [xs[1:3], xs[-3:], xs[:-1], xs[:], xs[9:], "héllo"[1:-1], len(sine(2, 1, 100)[0.25:-10])]`,
+
+		// effects, comparing audio by checksum since Inspect only shows its
+		// format
+		`checksum(gain(sine(440, 0.1, 8000, 0.5), 3))`,
+		`checksum(fade_out(fade_in(sine(440, 0.1, 8000), 0.01), 0.02))`,
+		`checksum(normalize(reverse(sine(440, 0.1, 8000, 0.5)), -1))`,
+		`checksum(resample(sine(440, 0.1, 8000, 0.5), 11025))`,
+		`let stereo = pan(sine(440, 0.1, 8000), 0.3); [checksum(stereo), checksum(pan(stereo, -0.5)), checksum(to_mono(stereo))]`,
+
+		// filters
+		noise + `[checksum(lowpass(noise, 2000)), checksum(highpass(noise, 100, 2))]`,
+		noise + `[checksum(bandpass(noise, 1000, 5)), checksum(notch(noise, 60))]`,
+		noise + `[checksum(peaking(noise, 3000, -6, 2)), checksum(low_shelf(noise, 100, 3)), checksum(high_shelf(noise, 8000, -9, 1))]`,
+
+		// spectral analysis
+		wave + `let x = fft(wave); [x[0][1], x[1][3], x[0][40]]`,
+		wave + `let x = fft(wave); checksum(ifft(x[0], x[1], 8000))`,
+		`spectrogram(sine(1000, 0.01, 8000), 20, 7, "hamming")[3]`,
+		`[peak_frequency(sine(440, 0.1, 8000)), peak_frequency([0, 1, 0, -1, 0, 1, 0, -1], 4)]`,
+
+		// meters
+		tone + `[rms(tone), peak(tone), crest_factor(tone)]`,
+		tone + `[integrated_lufs(tone), true_peak(tone), rms(silence(1, 8000))]`,
+
+		// time-based effects
+		saw + `[checksum(delay(saw, 0.05)), checksum(echo(saw, [[0.01, 0.5], [0.03, -0.2]]))]`,
+		saw + `[checksum(chorus(saw)), checksum(flanger(saw, 1, 0.004, 0.8, 0.6))]`,
+		saw + `[checksum(reverb(saw, 0.9, 0.2, 0.5)), checksum(reverb(pan(saw, 0.5)))]`,
+
+		// dynamics
+		voice + `let c = compress(voice, -12, 3, 0.005, 0.05, 6, 2, true); [checksum(c[0]), c[1][1000]]`,
+		voice + `[checksum(limit(voice, -6)), checksum(gate(voice, -30, 0.001, 0.02, 60))]`,
+		voice + `let e = expand(voice, -20, 2, true); [checksum(e[0]), e[1][3000]]`,
+
+		// editing
+		take + `[len(take), checksum(take)]`,
+		take + `let clips = split_on_silence(take, -50, 0.2); [len(clips), checksum(clips[1]), checksum(trim_silence(take))]`,
+		take + `let gaps = detect_silence(take, -50, 0.2); [len(gaps), gaps[1]["start"], gaps[1]["end"]]`,
+
+		// channels
+		surround + `let g = 0.7071; checksum(remix(surround, [[1, 0, g, 0, g, 0], [0, 1, g, 0, 0, g]]))`,
+		surround + `let channels = split_channels(surround); [len(channels), checksum(channels[5])]`,
+		surround + `merge_channels(split_channels(surround)) == surround`,
+
+		// file formats
+		`let aiff = load("../audio/testdata/stereo32f.aifc"); [checksum(aiff), aiff[3]]`,
+		`checksum(load_raw("../audio/testdata/stereo16be.raw", 44100, 2, "pcm16be"))`,
+		`load("../audio/testdata/mono12.aiff")[12]`,
+
+		`let a = sine(3, 1, 16); let b = square(2, 0.5, 16, 0.3); [a + b, a - b, a * b, a * 0.5, 0.5 * b, a / 3, -a, (a * b)[3], -(a + b) == b * -1 - a]`,
+	}
+
+	files, err := filepath.Glob("../vm/samples/*.vy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	more, err := filepath.Glob("../compiler/samples/*.vy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range append(files, more...) {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("could not read %s: %s", file, err)
+		}
+		inputs = append(inputs, string(content))
+	}
+
+	for _, input := range inputs {
+		program := parse(t, input)
+
+		var evaluated object.Object
+		evalOutput := captureStdout(t, func() {
+			evaluated = Eval(program, object.NewEnvironment())
+		})
+		if errObj, ok := evaluated.(*object.Error); ok {
+			t.Errorf("%q: evaluator error: %s", input, errObj.Message)
+			continue
+		}
+
+		comp := compiler.New()
+		if err := comp.Compile(program); err != nil {
+			t.Errorf("%q: compiler error: %s", input, err)
+			continue
+		}
+
+		machine := vm.New(comp.Bytecode())
+		vmOutput := captureStdout(t, func() {
+			err = machine.Run()
+		})
+		if err != nil {
+			t.Errorf("%q: vm error: %s", input, err)
+			continue
+		}
+
+		if evalOutput != vmOutput {
+			t.Errorf("%q: output differs.\nevaluator=%q\nvm       =%q", input, evalOutput, vmOutput)
+		}
+
+		if !endsWithExpression(program) {
+			continue
+		}
+
+		result := machine.LastPoppedStackElem()
+		if evaluated == nil || evaluated.Inspect() != result.Inspect() {
+			t.Errorf("%q: result differs.\nevaluator=%v\nvm       =%s", input, evaluated, result.Inspect())
+		}
+	}
+}
+
+func endsWithExpression(program *ast.Program) bool {
+	if len(program.Statements) == 0 {
+		return false
+	}
+
+	_, ok := program.Statements[len(program.Statements)-1].(*ast.ExpressionStatement)
+	return ok
+}
+
+// captureStdout returns what f prints, since puts writes to os.Stdout.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("could not create pipe: %s", err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		done <- buf.String()
+	}()
+
+	f()
+	w.Close()
+
+	return <-done
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(l.Errors()) != 0 || len(p.Errors()) != 0 {
+		t.Fatalf("%q: could not parse: %v %v", input, l.Errors(), p.Errors())
+	}
+
+	return program
+}
+
+func testEval(t *testing.T, input string) object.Object {
+	t.Helper()
+
+	return Eval(parse(t, input), object.NewEnvironment())
+}
+
+func testObject(t *testing.T, input string, expected interface{}, obj object.Object) {
+	t.Helper()
+
+	switch expected := expected.(type) {
+	case int:
+		result, ok := obj.(*object.Integer)
+		if !ok || result.Value != int64(expected) {
+			t.Errorf("%q: wrong result. want=%d, got=%T (%+v)", input, expected, obj, obj)
+		}
+	case float64:
+		result, ok := obj.(*object.Float)
+		if !ok || result.Value != expected {
+			t.Errorf("%q: wrong result. want=%g, got=%T (%+v)", input, expected, obj, obj)
+		}
+	case bool:
+		result, ok := obj.(*object.Boolean)
+		if !ok || result.Value != expected {
+			t.Errorf("%q: wrong result. want=%t, got=%T (%+v)", input, expected, obj, obj)
+		}
+	case string:
+		result, ok := obj.(*object.String)
+		if !ok || result.Value != expected {
+			t.Errorf("%q: wrong result. want=%q, got=%T (%+v)", input, expected, obj, obj)
+		}
+	case nil:
+		if obj != NULL {
+			t.Errorf("%q: result is not NULL. got=%T (%+v)", input, obj, obj)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package evaluator
+
+import (
+	"math"
+	"wavy/ast"
+	"wavy/object"
+)
+
+func evalPrefixExpression(node *ast.PrefixExpression, right object.Object) object.Object {
+	switch node.Operator {
+	case "!":
+		return nativeBoolToBooleanObject(!isTruthy(right))
+	case "-":
+		switch right := right.(type) {
+		case *object.Integer:
+			return &object.Integer{Value: -right.Value}
+		case *object.Float:
+			return &object.Float{Value: -right.Value}
+		case *object.Audio:
+			return right.Map(func(x float32) float32 { return -x })
+		default:
+			return newError(node.Pos(), "unsupported type for negation: %s", right.Type())
+		}
+	default:
+		return newError(node.Pos(), "unknown operator: %s%s", node.Operator, right.Type())
+	}
+}
+
+func evalInfixExpression(node *ast.InfixExpression, left, right object.Object) object.Object {
+	switch node.Operator {
+	case "+", "-", "*", "/", "%":
+		return evalArithmetic(node, left, right)
+	case "&&", "||":
+		// the left operand did not decide the result
+		return nativeBoolToBooleanObject(isTruthy(right))
+	case "<":
+		// the compiler swaps the operands of < and <= and emits > and >=
+		return evalComparison(node, ">", right, left)
+	case "<=":
+		return evalComparison(node, ">=", right, left)
+	default:
+		return evalComparison(node, node.Operator, left, right)
+	}
+}
+
+func evalArithmetic(node *ast.InfixExpression, left, right object.Object) object.Object {
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return evalIntegerArithmetic(node, left.(*object.Integer).Value, right.(*object.Integer).Value)
+	case isNumeric(left) && isNumeric(right):
+		return evalFloatArithmetic(node, toFloat(left), toFloat(right))
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		if node.Operator != "+" {
+			return newError(node.Pos(), "unknown string operator: %s", node.Operator)
+		}
+		return &object.String{Value: left.(*object.String).Value + right.(*object.String).Value}
+	case left.Type() == object.AUDIO_OBJ && right.Type() == object.AUDIO_OBJ:
+		return evalAudioArithmetic(node, left.(*object.Audio), right.(*object.Audio))
+	case left.Type() == object.AUDIO_OBJ && isNumeric(right) &&
+		(node.Operator == "*" || node.Operator == "/"):
+		return evalAudioGain(node, left.(*object.Audio), toFloat(right))
+	case isNumeric(left) && right.Type() == object.AUDIO_OBJ && node.Operator == "*":
+		return evalAudioGain(node, right.(*object.Audio), toFloat(left))
+	default:
+		return newError(node.Pos(), "unsupported types for binary operation: %s %s",
+			left.Type(), right.Type())
+	}
+}
+
+func evalAudioArithmetic(node *ast.InfixExpression, left, right *object.Audio) object.Object {
+	var f func(x, y float32) float32
+
+	switch node.Operator {
+	case "+":
+		f = func(x, y float32) float32 { return x + y }
+	case "-":
+		f = func(x, y float32) float32 { return x - y }
+	case "*":
+		f = func(x, y float32) float32 { return x * y }
+	default:
+		return newError(node.Pos(), "unsupported types for binary operation: %s %s",
+			left.Type(), right.Type())
+	}
+
+	result, err := left.Combine(right, f)
+	if err != nil {
+		return newError(node.Pos(), "%s", err)
+	}
+
+	return result
+}
+
+func evalAudioGain(node *ast.InfixExpression, audio *object.Audio, gain float64) object.Object {
+	if node.Operator == "/" {
+		if gain == 0 {
+			return newError(node.Pos(), "division by zero")
+		}
+		return audio.Map(func(x float32) float32 {
+			return float32(float64(x) / gain)
+		})
+	}
+
+	return audio.Map(func(x float32) float32 {
+		return float32(float64(x) * gain)
+	})
+}
+
+func evalIntegerArithmetic(node *ast.InfixExpression, left, right int64) object.Object {
+	switch node.Operator {
+	case "+":
+		return &object.Integer{Value: left + right}
+	case "-":
+		return &object.Integer{Value: left - right}
+	case "*":
+		return &object.Integer{Value: left * right}
+	case "%":
+		if right == 0 {
+			return newError(node.Pos(), "modulo by zero")
+		}
+		return &object.Integer{Value: left % right}
+	default:
+		if right == 0 {
+			return newError(node.Pos(), "division by zero")
+		}
+		return &object.Integer{Value: left / right}
+	}
+}
+
+func evalFloatArithmetic(node *ast.InfixExpression, left, right float64) object.Object {
+	switch node.Operator {
+	case "+":
+		return &object.Float{Value: left + right}
+	case "-":
+		return &object.Float{Value: left - right}
+	case "*":
+		return &object.Float{Value: left * right}
+	case "%":
+		return &object.Float{Value: math.Mod(left, right)}
+	default:
+		return &object.Float{Value: left / right}
+	}
+}
+
+// evalComparison handles ==, !=, > and >=. Numbers compare by value and audio
+// by content; everything else is compared by identity, as in the VM.
+func evalComparison(node *ast.InfixExpression, operator string, left, right object.Object) object.Object {
+	var equal, greater bool
+	ordered := false
+
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		l, r := left.(*object.Integer).Value, right.(*object.Integer).Value
+		equal, greater, ordered = l == r, l > r, true
+	case isNumeric(left) && isNumeric(right):
+		l, r := toFloat(left), toFloat(right)
+		equal, greater, ordered = l == r, l > r, true
+	case left.Type() == object.AUDIO_OBJ && right.Type() == object.AUDIO_OBJ:
+		equal = left.(*object.Audio).Equal(right.(*object.Audio))
+	default:
+		equal = left == right
+	}
+
+	switch {
+	case operator == "==":
+		return nativeBoolToBooleanObject(equal)
+	case operator == "!=":
+		return nativeBoolToBooleanObject(!equal)
+	case operator == ">" && ordered:
+		return nativeBoolToBooleanObject(greater)
+	case operator == ">=" && ordered:
+		return nativeBoolToBooleanObject(greater || equal)
+	default:
+		return newError(node.Pos(), "unknown operator: %s %s %s",
+			left.Type(), node.Operator, right.Type())
+	}
+}
+
+func evalIndexExpression(node *ast.IndexExpression, left, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		elements := left.(*object.Array).Elements
+		i := index.(*object.Integer).Value
+		if i < 0 || i >= int64(len(elements)) {
+			return NULL
+		}
+		return elements[i]
+
+	case left.Type() == object.HASH_OBJ:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError(node.Pos(), "unusable as hash key: %s", index.Type())
+		}
+
+		pair, ok := left.(*object.Hash).Pairs[key.HashKey()]
+		if !ok {
+			return NULL
+		}
+		return pair.Value
+
+	case left.Type() == object.AUDIO_OBJ && index.Type() == object.INTEGER_OBJ:
+		audio := left.(*object.Audio)
+		i := index.(*object.Integer).Value
+		if i < 0 || i >= int64(audio.Frames()) {
+			return NULL
+		}
+		return audio.FrameObject(int(i))
+
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		char, ok := left.(*object.String).CharAt(int(index.(*object.Integer).Value))
+		if !ok {
+			return NULL
+		}
+		return char
+
+	default:
+		return newError(node.Pos(), "index operator not supported: %s", left.Type())
+	}
+}
+
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return TRUE
+	}
+	return FALSE
+}
+
+func isTruthy(obj object.Object) bool {
+	switch obj := obj.(type) {
+	case *object.Boolean:
+		return obj.Value
+	case *object.Null:
+		return false
+	default:
+		return true
+	}
+}
+
+func isNumeric(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.Float:
+		return obj.Value
+	default:
+		return 0
+	}
+}
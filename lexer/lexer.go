@@ -2,11 +2,14 @@ package lexer
 
 import (
 	"fmt"
+	"strings"
+	"unicode/utf8"
 	"wavy/token"
 )
 
 type Lexer struct {
 	input        string
+	file         string
 	position     int
 	Row          int
 	Column       int
@@ -16,15 +19,24 @@ type Lexer struct {
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, Row: 1}
 	l.readChar()
 	return l
 }
 
+// NewWithFile returns a lexer whose token positions name the given file.
+func NewWithFile(input string, file string) *Lexer {
+	l := New(input)
+	l.file = file
+	return l
+}
+
 func (l *Lexer) NextToken() token.Token {
 	var tok token.Token
 
-	l.skipWhitespace()
+	l.skipWhitespaceAndComments()
+
+	pos := l.currentPosition()
 
 	switch l.ch {
 	case '=':
@@ -53,10 +65,34 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.SLASH, l.ch)
 	case '*':
 		tok = newToken(token.ASTERISK, l.ch)
+	case '%':
+		tok = newToken(token.PERCENT, l.ch)
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.LT_EQ)
+		} else {
+			tok = newToken(token.LT, l.ch)
+		}
 	case '>':
-		tok = newToken(token.GT, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.GT_EQ)
+		} else {
+			tok = newToken(token.GT, l.ch)
+		}
+	case '&':
+		if l.peekChar() == '&' {
+			tok = l.readTwoCharToken(token.AND)
+		} else {
+			tok = newToken(token.ILLEGAL, l.ch)
+			l.throwLexicalError("illegal character '&', did you mean '&&'?")
+		}
+	case '|':
+		if l.peekChar() == '|' {
+			tok = l.readTwoCharToken(token.OR)
+		} else {
+			tok = newToken(token.ILLEGAL, l.ch)
+			l.throwLexicalError("illegal character '|', did you mean '||'?")
+		}
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
 	case ':':
@@ -85,10 +121,11 @@ func (l *Lexer) NextToken() token.Token {
 		if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
+			tok.Pos = pos
 			return tok
 		} else if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
+			tok.Literal, tok.Type = l.readNumber()
+			tok.Pos = pos
 			return tok
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
@@ -97,15 +134,71 @@ func (l *Lexer) NextToken() token.Token {
 	}
 
 	l.readChar()
+	tok.Pos = pos
 	return tok
 }
 
+// readTwoCharToken consumes the current character and the next one, which
+// together make up a token such as <=.
+func (l *Lexer) readTwoCharToken(tokenType token.TokenType) token.Token {
+	ch := l.ch
+	l.readChar()
+	return token.Token{Type: tokenType, Literal: string(ch) + string(l.ch)}
+}
+
+func (l *Lexer) currentPosition() token.Position {
+	return token.Position{File: l.file, Line: l.Row, Column: l.Column}
+}
+
 func (l *Lexer) skipWhitespace() {
 	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
 		l.readChar()
 	}
 }
 
+// skipWhitespaceAndComments skips everything up to the next token, including
+// any number of // line comments and /* */ block comments.
+func (l *Lexer) skipWhitespaceAndComments() {
+	for {
+		l.skipWhitespace()
+
+		switch {
+		case l.ch == '/' && l.peekChar() == '/':
+			l.skipLineComment()
+		case l.ch == '/' && l.peekChar() == '*':
+			l.skipBlockComment()
+		default:
+			return
+		}
+	}
+}
+
+func (l *Lexer) skipLineComment() {
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
+}
+
+// skipBlockComment skips a /* */ comment, which may span several lines.
+// Block comments do not nest.
+func (l *Lexer) skipBlockComment() {
+	start := l.currentPosition()
+
+	l.readChar() // '/'
+	l.readChar() // '*'
+
+	for !(l.ch == '*' && l.peekChar() == '/') {
+		if l.ch == 0 {
+			l.lexicalErrorAt(start, "unterminated block comment")
+			return
+		}
+		l.readChar()
+	}
+
+	l.readChar() // '*'
+	l.readChar() // '/'
+}
+
 func (l *Lexer) readChar() {
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
@@ -140,34 +233,145 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readNumber() string {
+func (l *Lexer) readNumber() (string, token.TokenType) {
 	position := l.position
+	tokenType := token.TokenType(token.INT)
+
 	for isDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[position:l.position]
+
+	// a float needs digits on both sides of the decimal point
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
+		l.readChar()
+		for isDigit(l.ch) {
+			l.readChar()
+		}
+	}
+
+	return l.input[position:l.position], tokenType
 }
 
+// readString reads a string literal and returns its value with escape
+// sequences replaced by the characters they stand for.
 func (l *Lexer) readString() string {
-	start := l.position
-	startCol := l.Column
-	startRow := l.Row
-	position := l.position + 1
+	start := l.currentPosition()
+
+	var out strings.Builder
 	for {
 		l.readChar()
 		if l.ch == '"' {
 			break
 		}
 		if l.ch == 0 {
-			l.position = start
-			l.Column = startCol
-			l.Row = startRow
-			l.throwLexicalError("unterminated string")
+			l.lexicalErrorAt(start, "unterminated string")
 			break
 		}
+		if l.ch == '\\' {
+			l.readEscape(&out)
+			continue
+		}
 
+		out.WriteByte(l.ch)
+	}
+
+	return out.String()
+}
+
+var escapes = map[byte]byte{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'0':  0,
+	'\\': '\\',
+	'"':  '"',
+}
+
+// readEscape reads the escape sequence starting at the backslash under the
+// cursor and writes the character it stands for to out. It leaves the cursor
+// on the last character of the sequence.
+func (l *Lexer) readEscape(out *strings.Builder) {
+	pos := l.currentPosition()
+
+	l.readChar()
+	if ch, ok := escapes[l.ch]; ok {
+		out.WriteByte(ch)
+		return
+	}
+
+	switch l.ch {
+	case 'u':
+		r, ok := l.readUnicodeEscape()
+		if !ok {
+			l.lexicalErrorAt(pos, "invalid unicode escape")
+			return
+		}
+		out.WriteRune(r)
+	case 0:
+		// the unterminated string is reported by readString
+	default:
+		l.lexicalErrorAt(pos, fmt.Sprintf("invalid escape sequence '\\%c'", l.ch))
+	}
+}
+
+// readUnicodeEscape reads the code point of a \uXXXX or \u{X...} escape.
+func (l *Lexer) readUnicodeEscape() (rune, bool) {
+	braced := l.peekChar() == '{'
+	if braced {
+		l.readChar()
+	}
+
+	var value rune
+	digits := 0
+	for {
+		ch := l.peekChar()
+		if braced && ch == '}' {
+			l.readChar()
+			break
+		}
+
+		d, ok := hexValue(ch)
+		if !ok {
+			if braced {
+				return 0, false
+			}
+			break
+		}
+
+		l.readChar()
+		value = value*16 + d
+		digits++
+
+		if !braced && digits == 4 {
+			break
+		}
+		if digits > 6 {
+			return 0, false
+		}
+	}
+
+	if digits == 0 || !braced && digits != 4 {
+		return 0, false
+	}
+	if !utf8.ValidRune(value) {
+		return 0, false
+	}
+
+	return value, true
+}
+
+func hexValue(ch byte) (rune, bool) {
+	switch {
+	case '0' <= ch && ch <= '9':
+		return rune(ch - '0'), true
+	case 'a' <= ch && ch <= 'f':
+		return rune(ch-'a') + 10, true
+	case 'A' <= ch && ch <= 'F':
+		return rune(ch-'A') + 10, true
+	default:
+		return 0, false
 	}
-	return l.input[position:l.position]
 }
 
 func isLetter(ch byte) bool {
@@ -183,7 +387,11 @@ func newToken(tokenType token.TokenType, ch byte) token.Token {
 }
 
 func (l *Lexer) throwLexicalError(message string) {
-	msg := fmt.Sprintf("%s at line %d, position %d", message, l.Row, l.Column)
+	l.lexicalErrorAt(l.currentPosition(), message)
+}
+
+func (l *Lexer) lexicalErrorAt(pos token.Position, message string) {
+	msg := fmt.Sprintf("%s at %s", message, pos)
 	l.errors = append(l.errors, msg)
 }
 
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"wavy/token"
 )
@@ -62,3 +63,216 @@ func TestLexerOutput(t *testing.T) {
 
 	fmt.Print("\n==== Lexer Output End ====\n\n")
 }
+
+func TestNumbers(t *testing.T) {
+	input := `5 0.5 440.0 12.34.5 3.`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "5"},
+		{token.FLOAT, "0.5"},
+		{token.FLOAT, "440.0"},
+		{token.FLOAT, "12.34"},
+		{token.ILLEGAL, "."},
+		{token.INT, "5"},
+		{token.INT, "3"},
+		{token.ILLEGAL, "."},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// a line comment
+let x = 5; // trailing
+/* a block
+   comment */ x / 2
+/**/ x /* inline */ * 3 //`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{token.LET, "let", 2, 1},
+		{token.IDENT, "x", 2, 5},
+		{token.ASSIGN, "=", 2, 7},
+		{token.INT, "5", 2, 9},
+		{token.SEMICOLON, ";", 2, 10},
+		{token.IDENT, "x", 4, 15},
+		{token.SLASH, "/", 4, 17},
+		{token.INT, "2", 4, 19},
+		{token.IDENT, "x", 5, 6},
+		{token.ASTERISK, "*", 5, 21},
+		{token.INT, "3", 5, 23},
+		{token.EOF, "", 5, 27},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%s %q, got=%s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+
+		if tok.Pos.Line != tt.expectedLine || tok.Pos.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - wrong position for %q. expected=%d:%d, got=%d:%d",
+				i, tok.Literal, tt.expectedLine, tt.expectedColumn, tok.Pos.Line, tok.Pos.Column)
+		}
+	}
+
+	if len(l.Errors()) != 0 {
+		t.Errorf("unexpected lexer errors: %v", l.Errors())
+	}
+}
+
+func TestUnterminated(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"let x = 1;\n  /* never\nclosed", "unterminated block comment at line 2, position 3"},
+		{"x = \"abc", "unterminated string at line 1, position 5"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		}
+
+		if len(l.Errors()) == 0 {
+			t.Errorf("%q: expected a lexer error", tt.input)
+			continue
+		}
+
+		if !strings.HasPrefix(l.Errors()[0], tt.expectedError) {
+			t.Errorf("%q: wrong error. expected=%q, got=%q",
+				tt.input, tt.expectedError, l.Errors()[0])
+		}
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"plain"`, "plain"},
+		{`"a\nb\tc\rd"`, "a\nb\tc\rd"},
+		{`"say \"hi\""`, `say "hi"`},
+		{`"back\\slash"`, `back\slash`},
+		{`"nul\0"`, "nul\x00"},
+		{`"café"`, "café"},
+		{`"\u{1F3B5} track"`, "\U0001F3B5 track"},
+		{`"Beyoncé – Halo.wav"`, "Beyoncé – Halo.wav"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != token.STRING {
+			t.Fatalf("%s: token type wrong. expected=%q, got=%q", tt.input, token.STRING, tok.Type)
+		}
+		if tok.Literal != tt.expected {
+			t.Errorf("%s: literal wrong. expected=%q, got=%q", tt.input, tt.expected, tok.Literal)
+		}
+		if len(l.Errors()) != 0 {
+			t.Errorf("%s: unexpected errors: %v", tt.input, l.Errors())
+		}
+	}
+}
+
+func TestInvalidStringEscapes(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{`"bad \q escape"`, `invalid escape sequence '\q' at line 1, position 6`},
+		{`"\u12"`, "invalid unicode escape at line 1, position 2"},
+		{`"\u{110000}"`, "invalid unicode escape at line 1, position 2"},
+		{`"\uD800"`, "invalid unicode escape at line 1, position 2"},
+		{`"\u{12"`, "invalid unicode escape at line 1, position 2"},
+		{`"ends with \`, "unterminated string at line 1, position 1"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		}
+
+		if len(l.Errors()) == 0 {
+			t.Errorf("%s: expected a lexer error", tt.input)
+			continue
+		}
+		if l.Errors()[0] != tt.expectedError {
+			t.Errorf("%s: wrong error. expected=%q, got=%q", tt.input, tt.expectedError, l.Errors()[0])
+		}
+	}
+}
+
+func TestOperators(t *testing.T) {
+	input := `a <= b >= c < d > e % f && g || h & i | j`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.LT_EQ, "<="},
+		{token.IDENT, "b"},
+		{token.GT_EQ, ">="},
+		{token.IDENT, "c"},
+		{token.LT, "<"},
+		{token.IDENT, "d"},
+		{token.GT, ">"},
+		{token.IDENT, "e"},
+		{token.PERCENT, "%"},
+		{token.IDENT, "f"},
+		{token.AND, "&&"},
+		{token.IDENT, "g"},
+		{token.OR, "||"},
+		{token.IDENT, "h"},
+		{token.ILLEGAL, "&"},
+		{token.IDENT, "i"},
+		{token.ILLEGAL, "|"},
+		{token.IDENT, "j"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%s %q, got=%s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+
+	if len(l.Errors()) != 2 {
+		t.Errorf("expected 2 errors for the single & and |, got=%v", l.Errors())
+	}
+}
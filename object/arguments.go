@@ -0,0 +1,104 @@
+package object
+
+import (
+	"fmt"
+	"math"
+)
+
+var ordinals = []string{"first", "second", "third", "fourth", "fifth", "sixth", "seventh", "eighth"}
+
+// argumentName names argument i in error messages: "argument" when it is
+// the only one, and "first argument", "second argument" and so on otherwise.
+func argumentName(args []Object, i int) string {
+	if len(args) == 1 {
+		return "argument"
+	}
+	if i < len(ordinals) {
+		return ordinals[i] + " argument"
+	}
+	return fmt.Sprintf("argument %d", i+1)
+}
+
+// checkArgumentCount returns an error unless min <= len(args) <= max.
+func checkArgumentCount(args []Object, min, max int) *Error {
+	if len(args) >= min && len(args) <= max {
+		return nil
+	}
+
+	switch {
+	case min == max:
+		return newError("wrong number of arguments. got=%d, want=%d", len(args), min)
+	case max == min+1:
+		return newError("wrong number of arguments. got=%d, want=%d or %d", len(args), min, max)
+	case max == math.MaxInt:
+		return newError("wrong number of arguments. got=%d, want at least %d", len(args), min)
+	default:
+		return newError("wrong number of arguments. got=%d, want=%d to %d", len(args), min, max)
+	}
+}
+
+func audioArgument(name string, args []Object, i int) (*Audio, *Error) {
+	a, ok := args[i].(*Audio)
+	if !ok {
+		return nil, newError("%s to `%s` must be AUDIO, got %s",
+			argumentName(args, i), name, args[i].Type())
+	}
+	return a, nil
+}
+
+func numberArgument(name string, args []Object, i int) (float64, *Error) {
+	value, ok := toFloat(args[i])
+	if !ok {
+		return 0, newError("%s to `%s` must be INTEGER or FLOAT, got %s",
+			argumentName(args, i), name, args[i].Type())
+	}
+	return value, nil
+}
+
+func integerArgument(name string, args []Object, i int) (int64, *Error) {
+	integer, ok := args[i].(*Integer)
+	if !ok {
+		return 0, newError("%s to `%s` must be INTEGER, got %s",
+			argumentName(args, i), name, args[i].Type())
+	}
+	return integer.Value, nil
+}
+
+func stringArgument(name string, args []Object, i int) (string, *Error) {
+	str, ok := args[i].(*String)
+	if !ok {
+		return "", newError("%s to `%s` must be STRING, got %s",
+			argumentName(args, i), name, args[i].Type())
+	}
+	return str.Value, nil
+}
+
+// toFloat returns the value of an INTEGER or FLOAT.
+func toFloat(obj Object) (float64, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return float64(obj.Value), true
+	case *Float:
+		return obj.Value, true
+	default:
+		return 0, false
+	}
+}
+
+// optionalNumbers reads the optional number arguments from args[from] on
+// into values, in order, leaving the values without an argument at their
+// defaults.
+func optionalNumbers(name string, args []Object, from int, values ...*float64) *Error {
+	for i, value := range values {
+		if from+i >= len(args) {
+			break
+		}
+
+		number, err := numberArgument(name, args, from+i)
+		if err != nil {
+			return err
+		}
+		*value = number
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package object
+
+import (
+	"fmt"
+	"wavy/audio"
+)
+
+func audioFromBuffer(b *audio.Buffer) *Audio {
+	return &Audio{
+		Samples:    b.Samples,
+		SampleRate: b.SampleRate,
+		Channels:   b.Channels,
+		BitDepth:   b.BitDepth,
+	}
+}
+
+func (a *Audio) buffer() *audio.Buffer {
+	return &audio.Buffer{
+		Samples:    a.Samples,
+		SampleRate: a.SampleRate,
+		Channels:   a.Channels,
+		BitDepth:   a.BitDepth,
+	}
+}
+
+func loadBuiltin(args ...Object) Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1",
+			len(args))
+	}
+	if args[0].Type() != STRING_OBJ {
+		return newError("argument to `load` must be STRING, got %s",
+			args[0].Type())
+	}
+
+	path := args[0].(*String).Value
+
+	b, err := audio.ReadFile(path)
+	if err != nil {
+		return newError("could not load %q: %s", path, err)
+	}
+
+	return audioFromBuffer(b)
+}
+
+func saveBuiltin(args ...Object) Object {
+	if len(args) != 2 && len(args) != 3 {
+		return newError("wrong number of arguments. got=%d, want=2 or 3",
+			len(args))
+	}
+	if args[0].Type() != AUDIO_OBJ {
+		return newError("first argument to `save` must be AUDIO, got %s",
+			args[0].Type())
+	}
+	if args[1].Type() != STRING_OBJ {
+		return newError("second argument to `save` must be STRING, got %s",
+			args[1].Type())
+	}
+
+	a := args[0].(*Audio)
+	path := args[1].(*String).Value
+
+	enc := audio.DefaultEncoding(a.BitDepth)
+	if len(args) == 3 {
+		if args[2].Type() != STRING_OBJ {
+			return newError("third argument to `save` must be STRING, got %s",
+				args[2].Type())
+		}
+
+		var err error
+		enc, err = audio.ParseEncoding(args[2].(*String).Value)
+		if err != nil {
+			return newError("could not save %q: %s", path, err)
+		}
+	}
+
+	err := audio.WriteFile(path, a.buffer(), enc)
+	if err != nil {
+		return newError("could not save %q: %s", path, err)
+	}
+
+	return nil
+}
+
+// load_raw(path, sample_rate, channels, format) reads headerless PCM, which
+// records none of its format, in a format such as "pcm16le" or "float32be".
+func loadRawBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 4, 4); err != nil {
+		return err
+	}
+	path, err := stringArgument("load_raw", args, 0)
+	if err != nil {
+		return err
+	}
+	rate, err := integerArgument("load_raw", args, 1)
+	if err != nil {
+		return err
+	}
+	channels, err := integerArgument("load_raw", args, 2)
+	if err != nil {
+		return err
+	}
+	name, err := stringArgument("load_raw", args, 3)
+	if err != nil {
+		return err
+	}
+
+	format, parseErr := audio.ParseRawFormat(name)
+	if parseErr != nil {
+		return newError("could not load %q: %s", path, parseErr)
+	}
+
+	b, readErr := audio.ReadRawFile(path, int(rate), int(channels), format)
+	if readErr != nil {
+		return newError("could not load %q: %s", path, readErr)
+	}
+
+	return audioFromBuffer(b)
+}
+
+// save_raw(audio, path, format) writes the samples of audio with no header.
+func saveRawBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 3, 3); err != nil {
+		return err
+	}
+	a, err := audioArgument("save_raw", args, 0)
+	if err != nil {
+		return err
+	}
+	path, err := stringArgument("save_raw", args, 1)
+	if err != nil {
+		return err
+	}
+	name, err := stringArgument("save_raw", args, 2)
+	if err != nil {
+		return err
+	}
+
+	format, parseErr := audio.ParseRawFormat(name)
+	if parseErr != nil {
+		return newError("could not save %q: %s", path, parseErr)
+	}
+
+	if writeErr := audio.WriteRawFile(path, a.buffer(), format); writeErr != nil {
+		return newError("could not save %q: %s", path, writeErr)
+	}
+
+	return nil
+}
+
+// Combine applies f to each pair of samples of a and other, as the audio
+// operators do. The shorter buffer is padded with silence, so mixing a short
+// clip into a long one keeps the tail of the long one. Both buffers must
+// have the same sample rate and number of channels.
+func (a *Audio) Combine(other *Audio, f func(x, y float32) float32) (*Audio, error) {
+	if a.SampleRate != other.SampleRate {
+		return nil, fmt.Errorf("mismatched sample rates: %dHz and %dHz",
+			a.SampleRate, other.SampleRate)
+	}
+	if a.Channels != other.Channels {
+		return nil, fmt.Errorf("mismatched channel counts: %d and %d",
+			a.Channels, other.Channels)
+	}
+
+	samples := make([]float32, max(len(a.Samples), len(other.Samples)))
+	for i := range samples {
+		var x, y float32
+		if i < len(a.Samples) {
+			x = a.Samples[i]
+		}
+		if i < len(other.Samples) {
+			y = other.Samples[i]
+		}
+		samples[i] = f(x, y)
+	}
+
+	return &Audio{
+		Samples:    samples,
+		SampleRate: a.SampleRate,
+		Channels:   a.Channels,
+		BitDepth:   max(a.BitDepth, other.BitDepth),
+	}, nil
+}
+
+// Map returns a copy of a with f applied to every sample.
+func (a *Audio) Map(f func(x float32) float32) *Audio {
+	samples := make([]float32, len(a.Samples))
+	for i, s := range a.Samples {
+		samples[i] = f(s)
+	}
+
+	return &Audio{
+		Samples:    samples,
+		SampleRate: a.SampleRate,
+		Channels:   a.Channels,
+		BitDepth:   a.BitDepth,
+	}
+}
@@ -0,0 +1,163 @@
+package object
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAndSave(t *testing.T) {
+	dir := t.TempDir()
+	path := &String{Value: filepath.Join(dir, "out.wav")}
+
+	original := &Audio{
+		Samples:    []float32{0, 0.5, -0.5, 0.25},
+		SampleRate: 22050,
+		Channels:   2,
+		BitDepth:   16,
+	}
+
+	if result := saveBuiltin(original, path); result != nil {
+		t.Fatalf("save returned %s", result.Inspect())
+	}
+
+	loaded, ok := loadBuiltin(path).(*Audio)
+	if !ok {
+		t.Fatalf("load did not return Audio. got=%s", loadBuiltin(path).Inspect())
+	}
+
+	if !loaded.Equal(original) {
+		t.Errorf("loaded audio differs. want=%v, got=%v", original, loaded)
+	}
+
+	if result := saveBuiltin(original, path, &String{Value: "float32"}); result != nil {
+		t.Fatalf("save returned %s", result.Inspect())
+	}
+
+	loaded = loadBuiltin(path).(*Audio)
+	if loaded.BitDepth != 32 {
+		t.Errorf("wrong bit depth after saving as float32. got=%d", loaded.BitDepth)
+	}
+}
+
+func TestLoadDetectsContainer(t *testing.T) {
+	dir := t.TempDir()
+	original := &Audio{Samples: []float32{0, 0.5, -0.5, 0.25}, SampleRate: 44100, Channels: 1, BitDepth: 24}
+
+	// load must not rely on the extension, so an AIFF file is saved under
+	// its proper name and then copied to a misleading one
+	aiff := filepath.Join(dir, "out.aiff")
+	if result := saveBuiltin(original, &String{Value: aiff}); result != nil {
+		t.Fatalf("save returned %s", result.Inspect())
+	}
+	data, err := os.ReadFile(aiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data[0:4]) != "FORM" {
+		t.Fatalf("save did not write AIFF. got magic %q", data[0:4])
+	}
+	misnamed := filepath.Join(dir, "aiff.wav")
+	if err := os.WriteFile(misnamed, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, ok := loadBuiltin(&String{Value: misnamed}).(*Audio)
+	if !ok || !loaded.Equal(original) {
+		t.Errorf("loaded audio differs. want=%v, got=%v", original, loadBuiltin(&String{Value: misnamed}))
+	}
+}
+
+func TestLoadRawAndSaveRaw(t *testing.T) {
+	dir := t.TempDir()
+	original := &Audio{Samples: []float32{0, 0.5, -0.5, 0.25}, SampleRate: 22050, Channels: 2, BitDepth: 16}
+
+	for _, format := range []string{"pcm16le", "pcm16be", "pcm24be", "float32le", "pcm8u"} {
+		path := &String{Value: filepath.Join(dir, format+".raw")}
+		if result := saveRawBuiltin(original, path, &String{Value: format}); result != nil {
+			t.Fatalf("save_raw returned %s", result.Inspect())
+		}
+
+		result := loadRawBuiltin(path, &Integer{Value: 22050}, &Integer{Value: 2}, &String{Value: format})
+		loaded, ok := result.(*Audio)
+		if !ok {
+			t.Fatalf("load_raw did not return Audio. got=%s", result.Inspect())
+		}
+
+		if loaded.SampleRate != 22050 || loaded.Channels != 2 {
+			t.Errorf("%s: wrong format. got=%dHz %dch", format, loaded.SampleRate, loaded.Channels)
+		}
+		for i, s := range loaded.Samples {
+			if s != original.Samples[i] {
+				t.Errorf("%s: wrong sample %d. want=%g, got=%g", format, i, original.Samples[i], s)
+			}
+		}
+	}
+}
+
+func TestLoadAndSaveErrors(t *testing.T) {
+	dir := t.TempDir()
+	audio := &Audio{Samples: []float32{0}, SampleRate: 8000, Channels: 1, BitDepth: 16}
+	raw := filepath.Join(dir, "empty.raw")
+	if err := os.WriteFile(raw, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		result   Object
+		expected string
+	}{
+		{loadBuiltin(), "wrong number of arguments. got=0, want=1"},
+		{loadBuiltin(&Integer{Value: 1}), "argument to `load` must be STRING, got INTEGER"},
+		{loadBuiltin(&String{Value: filepath.Join(dir, "missing.wav")}), "no such file or directory"},
+		{saveBuiltin(audio), "wrong number of arguments. got=1, want=2 or 3"},
+		{saveBuiltin(&Integer{Value: 1}, &String{Value: "x.wav"}), "first argument to `save` must be AUDIO, got INTEGER"},
+		{
+			saveBuiltin(audio, &String{Value: filepath.Join(dir, "x.wav")}, &String{Value: "mp3"}),
+			`unknown encoding "mp3"`,
+		},
+		{loadRawBuiltin(&String{Value: "x.raw"}), "wrong number of arguments. got=1, want=4"},
+		{
+			loadRawBuiltin(&String{Value: "x.raw"}, &Float{Value: 8000}, &Integer{Value: 1}, &String{Value: "pcm16le"}),
+			"second argument to `load_raw` must be INTEGER, got FLOAT",
+		},
+		{
+			loadRawBuiltin(&String{Value: "x.raw"}, &Integer{Value: 8000}, &Integer{Value: 1}, &String{Value: "pcm16"}),
+			`could not load "x.raw": raw format "pcm16" must end in "le" or "be"`,
+		},
+		{
+			loadRawBuiltin(&String{Value: "x.raw"}, &Integer{Value: 8000}, &Integer{Value: 0}, &String{Value: "pcm16le"}),
+			"no such file or directory",
+		},
+		{
+			loadRawBuiltin(&String{Value: raw}, &Integer{Value: 8000}, &Integer{Value: 0}, &String{Value: "pcm16le"}),
+			"raw: channel count must be positive, got 0",
+		},
+		{
+			loadRawBuiltin(&String{Value: raw}, &Integer{Value: 8000}, &Integer{Value: 1 << 62}, &String{Value: "pcm16le"}),
+			"raw: channel count must be at most 65535, got 4611686018427387904",
+		},
+		{saveRawBuiltin(audio, &String{Value: raw}), "wrong number of arguments. got=2, want=3"},
+		{
+			saveRawBuiltin(audio, &Integer{Value: 1}, &String{Value: "pcm16le"}),
+			"second argument to `save_raw` must be STRING, got INTEGER",
+		},
+		{
+			saveRawBuiltin(audio, &String{Value: raw}, &String{Value: "wav"}),
+			`could not save "` + raw + `": raw format "wav" must end in "le" or "be"`,
+		},
+	}
+
+	for _, tt := range tests {
+		err, ok := tt.result.(*Error)
+		if !ok {
+			t.Errorf("result is not Error. got=%T (%+v)", tt.result, tt.result)
+			continue
+		}
+
+		if !strings.Contains(err.Message, tt.expected) {
+			t.Errorf("wrong error message. want=%q, got=%q", tt.expected, err.Message)
+		}
+	}
+}
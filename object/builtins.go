@@ -18,7 +18,9 @@ var Builtins = []struct {
 			case *Array:
 				return &Integer{Value: int64(len(arg.Elements))}
 			case *String:
-				return &Integer{Value: int64(len(arg.Value))}
+				return &Integer{Value: int64(arg.Len())}
+			case *Audio:
+				return &Integer{Value: int64(arg.Frames())}
 			default:
 				return newError("argument to `len` not supported, got %s",
 					args[0].Type())
@@ -127,6 +129,58 @@ var Builtins = []struct {
 		},
 		},
 	},
+	{"load", &Builtin{Fn: loadBuiltin}},
+	{"save", &Builtin{Fn: saveBuiltin}},
+	{"sine", &Builtin{Fn: oscillator("sine", sineWave)}},
+	{"square", &Builtin{Fn: oscillator("square", squareWave)}},
+	{"sawtooth", &Builtin{Fn: oscillator("sawtooth", sawtoothWave)}},
+	{"triangle", &Builtin{Fn: oscillator("triangle", triangleWave)}},
+	{"white_noise", &Builtin{Fn: noise("white_noise", false)}},
+	{"pink_noise", &Builtin{Fn: noise("pink_noise", true)}},
+	{"silence", &Builtin{Fn: silenceBuiltin}},
+	{"gain", &Builtin{Fn: gainBuiltin}},
+	{"fade_in", &Builtin{Fn: fadeInBuiltin}},
+	{"fade_out", &Builtin{Fn: fadeOutBuiltin}},
+	{"normalize", &Builtin{Fn: normalizeBuiltin}},
+	{"reverse", &Builtin{Fn: reverseBuiltin}},
+	{"resample", &Builtin{Fn: resampleBuiltin}},
+	{"to_mono", &Builtin{Fn: toMonoBuiltin}},
+	{"pan", &Builtin{Fn: panBuiltin}},
+	{"lowpass", &Builtin{Fn: lowpassBuiltin}},
+	{"highpass", &Builtin{Fn: highpassBuiltin}},
+	{"bandpass", &Builtin{Fn: bandpassBuiltin}},
+	{"notch", &Builtin{Fn: notchBuiltin}},
+	{"peaking", &Builtin{Fn: peakingBuiltin}},
+	{"low_shelf", &Builtin{Fn: lowShelfBuiltin}},
+	{"high_shelf", &Builtin{Fn: highShelfBuiltin}},
+	{"fft", &Builtin{Fn: fftBuiltin}},
+	{"ifft", &Builtin{Fn: ifftBuiltin}},
+	{"spectrogram", &Builtin{Fn: spectrogramBuiltin}},
+	{"peak_frequency", &Builtin{Fn: peakFrequencyBuiltin}},
+	{"rms", &Builtin{Fn: rmsBuiltin}},
+	{"peak", &Builtin{Fn: peakBuiltin}},
+	{"crest_factor", &Builtin{Fn: crestFactorBuiltin}},
+	{"integrated_lufs", &Builtin{Fn: integratedLUFSBuiltin}},
+	{"true_peak", &Builtin{Fn: truePeakBuiltin}},
+	{"delay", &Builtin{Fn: delayBuiltin}},
+	{"echo", &Builtin{Fn: echoBuiltin}},
+	{"chorus", &Builtin{Fn: chorusBuiltin}},
+	{"flanger", &Builtin{Fn: flangerBuiltin}},
+	{"reverb", &Builtin{Fn: reverbBuiltin}},
+	{"checksum", &Builtin{Fn: checksumBuiltin}},
+	{"compress", &Builtin{Fn: compressBuiltin}},
+	{"limit", &Builtin{Fn: limitBuiltin}},
+	{"gate", &Builtin{Fn: gateBuiltin}},
+	{"expand", &Builtin{Fn: expandBuiltin}},
+	{"concat", &Builtin{Fn: concatBuiltin}},
+	{"split_on_silence", &Builtin{Fn: splitOnSilenceBuiltin}},
+	{"trim_silence", &Builtin{Fn: trimSilenceBuiltin}},
+	{"detect_silence", &Builtin{Fn: detectSilenceBuiltin}},
+	{"split_channels", &Builtin{Fn: splitChannelsBuiltin}},
+	{"merge_channels", &Builtin{Fn: mergeChannelsBuiltin}},
+	{"remix", &Builtin{Fn: remixBuiltin}},
+	{"load_raw", &Builtin{Fn: loadRawBuiltin}},
+	{"save_raw", &Builtin{Fn: saveRawBuiltin}},
 }
 
 func newError(format string, a ...interface{}) *Error {
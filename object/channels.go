@@ -0,0 +1,78 @@
+package object
+
+import (
+	"wavy/audio"
+	"wavy/dsp"
+)
+
+// split_channels(audio) returns an array with each channel as mono audio.
+func splitChannelsBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 1, 1); err != nil {
+		return err
+	}
+	a, err := audioArgument("split_channels", args, 0)
+	if err != nil {
+		return err
+	}
+
+	channels := dsp.SplitChannels(a.buffer())
+	elements := make([]Object, len(channels))
+	for i, c := range channels {
+		elements[i] = audioFromBuffer(c)
+	}
+	return &Array{Elements: elements}
+}
+
+// merge_channels(array) interleaves an array of audio into one buffer.
+func mergeChannelsBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 1, 1); err != nil {
+		return err
+	}
+	array, ok := args[0].(*Array)
+	if !ok {
+		return newError("argument to `merge_channels` must be ARRAY, got %s", args[0].Type())
+	}
+
+	buffers := make([]*audio.Buffer, len(array.Elements))
+	for i, element := range array.Elements {
+		a, ok := element.(*Audio)
+		if !ok {
+			return newError("argument to `merge_channels` must be an ARRAY of AUDIO, got an ARRAY containing %s",
+				element.Type())
+		}
+		buffers[i] = a.buffer()
+	}
+
+	return processed(dsp.MergeChannels(buffers))
+}
+
+// remix(audio, matrix), where matrix is an array with one array of gains
+// per output channel and one gain per input channel in each.
+func remixBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 2, 2); err != nil {
+		return err
+	}
+	a, err := audioArgument("remix", args, 0)
+	if err != nil {
+		return err
+	}
+	array, ok := args[1].(*Array)
+	if !ok {
+		return newError("second argument to `remix` must be ARRAY, got %s", args[1].Type())
+	}
+
+	matrix := make([][]float64, len(array.Elements))
+	for i, element := range array.Elements {
+		row, ok := element.(*Array)
+		if !ok {
+			return newError("remix matrix rows must be arrays of gains, got %s", element.Inspect())
+		}
+		gains, err := numberElements("remix", row)
+		if err != nil {
+			return err
+		}
+		matrix[i] = gains
+	}
+
+	return processed(dsp.Remix(a.buffer(), matrix))
+}
@@ -0,0 +1,89 @@
+package object
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChannelBuiltins(t *testing.T) {
+	stereo := &Audio{Samples: []float32{0.5, -0.5, 0.25, 0}, SampleRate: 2, Channels: 2, BitDepth: 16}
+	left := &Audio{Samples: []float32{0.5, 0.25}, SampleRate: 2, Channels: 1, BitDepth: 16}
+	right := &Audio{Samples: []float32{-0.5, 0}, SampleRate: 2, Channels: 1, BitDepth: 16}
+
+	channels, ok := splitChannelsBuiltin(stereo).(*Array)
+	if !ok || len(channels.Elements) != 2 {
+		t.Fatalf("split_channels should return two channels. got=%v", channels)
+	}
+	if !channels.Elements[0].(*Audio).Equal(left) || !channels.Elements[1].(*Audio).Equal(right) {
+		t.Errorf("wrong channels. got=%v", channels.Inspect())
+	}
+
+	tests := []struct {
+		name     string
+		args     []Object
+		expected *Audio
+	}{
+		{"merge_channels", []Object{channels}, stereo},
+		{"merge_channels", []Object{&Array{Elements: []Object{right, left}}},
+			&Audio{Samples: []float32{-0.5, 0.5, 0, 0.25}, SampleRate: 2, Channels: 2, BitDepth: 16}},
+		{"remix", []Object{stereo, &Array{Elements: []Object{
+			&Array{Elements: []Object{&Float{Value: 0.5}, &Float{Value: 0.5}}},
+		}}}, &Audio{Samples: []float32{0, 0.125}, SampleRate: 2, Channels: 1, BitDepth: 16}},
+		{"remix", []Object{left, &Array{Elements: []Object{
+			&Array{Elements: []Object{&Integer{Value: 1}}},
+			&Array{Elements: []Object{&Integer{Value: -1}}},
+			&Array{Elements: []Object{&Integer{Value: 0}}},
+		}}}, &Audio{Samples: []float32{0.5, -0.5, 0, 0.25, -0.25, 0}, SampleRate: 2, Channels: 3, BitDepth: 16}},
+	}
+
+	for _, tt := range tests {
+		result := GetBuiltinByName(tt.name).Fn(tt.args...)
+
+		audio, ok := result.(*Audio)
+		if !ok {
+			t.Errorf("%s: result is not Audio. got=%s", tt.name, result.Inspect())
+			continue
+		}
+
+		if !audio.Equal(tt.expected) {
+			t.Errorf("%s: wrong result. want=%v, got=%v", tt.name, tt.expected, audio)
+		}
+	}
+}
+
+func TestChannelBuiltinErrors(t *testing.T) {
+	mono := &Audio{Samples: []float32{0.5, -0.25}, SampleRate: 8000, Channels: 1, BitDepth: 16}
+	other := &Audio{Samples: []float32{0.5, -0.25}, SampleRate: 44100, Channels: 1, BitDepth: 16}
+
+	tests := []struct {
+		name     string
+		args     []Object
+		expected string
+	}{
+		{"split_channels", []Object{&Integer{Value: 2}}, "argument to `split_channels` must be AUDIO, got INTEGER"},
+		{"merge_channels", []Object{mono}, "argument to `merge_channels` must be ARRAY, got AUDIO"},
+		{"merge_channels", []Object{&Array{Elements: []Object{mono, &Integer{Value: 1}}}}, "must be an ARRAY of AUDIO, got an ARRAY containing INTEGER"},
+		{"merge_channels", []Object{&Array{}}, "nothing to merge"},
+		{"merge_channels", []Object{&Array{Elements: []Object{mono, other}}}, "mismatched sample rates: 8000Hz and 44100Hz"},
+		{"remix", []Object{mono}, "wrong number of arguments. got=1, want=2"},
+		{"remix", []Object{mono, &Integer{Value: 1}}, "second argument to `remix` must be ARRAY, got INTEGER"},
+		{"remix", []Object{mono, &Array{Elements: []Object{&Integer{Value: 1}}}}, "remix matrix rows must be arrays of gains, got 1"},
+		{"remix", []Object{mono, &Array{Elements: []Object{&Array{Elements: []Object{&String{Value: "x"}}}}}}, "elements of an array passed to `remix` must be INTEGER or FLOAT, got STRING"},
+		{"remix", []Object{mono, &Array{Elements: []Object{&Array{}}}}, "row 1 of the remix matrix has 0 gains for 1-channel audio"},
+		{"remix", []Object{mono, &Array{}}, "remix matrix must have at least one row"},
+	}
+
+	for _, tt := range tests {
+		result := GetBuiltinByName(tt.name).Fn(tt.args...)
+
+		err, ok := result.(*Error)
+		if !ok {
+			t.Errorf("%s: result is not Error. got=%T (%+v)", tt.name, result, result)
+			continue
+		}
+
+		if !strings.Contains(err.Message, tt.expected) {
+			t.Errorf("%s: wrong error message. want=%q, got=%q", tt.name, tt.expected, err.Message)
+		}
+	}
+}
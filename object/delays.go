@@ -0,0 +1,155 @@
+package object
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"wavy/audio"
+	"wavy/dsp"
+)
+
+// Default parameters of the time-based effects.
+const (
+	defaultFeedback = 0.5
+	defaultMix      = 0.5
+
+	defaultChorusRate  = 1.5
+	defaultChorusDepth = 0.003
+
+	defaultFlangerRate  = 0.25
+	defaultFlangerDepth = 0.002
+
+	defaultRoomSize  = 0.5
+	defaultDamping   = 0.5
+	defaultReverbMix = 0.3
+)
+
+// delay(audio, seconds[, feedback[, mix]])
+func delayBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 2, 4); err != nil {
+		return err
+	}
+	a, err := audioArgument("delay", args, 0)
+	if err != nil {
+		return err
+	}
+	secs, err := numberArgument("delay", args, 1)
+	if err != nil {
+		return err
+	}
+
+	feedback, mix := defaultFeedback, defaultMix
+	if err := optionalNumbers("delay", args, 2, &feedback, &mix); err != nil {
+		return err
+	}
+
+	return processed(dsp.Delay(a.buffer(), secs, feedback, mix))
+}
+
+// echo(audio, taps), where taps is an array of [seconds, gain] pairs.
+func echoBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 2, 2); err != nil {
+		return err
+	}
+	a, err := audioArgument("echo", args, 0)
+	if err != nil {
+		return err
+	}
+	array, ok := args[1].(*Array)
+	if !ok {
+		return newError("second argument to `echo` must be ARRAY, got %s", args[1].Type())
+	}
+
+	taps := make([]dsp.Tap, len(array.Elements))
+	for i, element := range array.Elements {
+		pair, ok := element.(*Array)
+		if !ok || len(pair.Elements) != 2 {
+			return newError("echo taps must be [seconds, gain] pairs, got %s", element.Inspect())
+		}
+
+		secs, ok := toFloat(pair.Elements[0])
+		gain, ok2 := toFloat(pair.Elements[1])
+		if !ok || !ok2 {
+			return newError("echo taps must be [seconds, gain] pairs, got %s", element.Inspect())
+		}
+
+		taps[i] = dsp.Tap{Delay: secs, Gain: gain}
+	}
+
+	return processed(dsp.Echo(a.buffer(), taps))
+}
+
+// chorus(audio[, rate[, depth[, mix]]])
+func chorusBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 1, 4); err != nil {
+		return err
+	}
+	a, err := audioArgument("chorus", args, 0)
+	if err != nil {
+		return err
+	}
+
+	rate, depth, mix := defaultChorusRate, defaultChorusDepth, defaultMix
+	if err := optionalNumbers("chorus", args, 1, &rate, &depth, &mix); err != nil {
+		return err
+	}
+
+	return processed(dsp.Chorus(a.buffer(), rate, depth, mix))
+}
+
+// flanger(audio[, rate[, depth[, feedback[, mix]]]])
+func flangerBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 1, 5); err != nil {
+		return err
+	}
+	a, err := audioArgument("flanger", args, 0)
+	if err != nil {
+		return err
+	}
+
+	rate, depth := defaultFlangerRate, defaultFlangerDepth
+	feedback, mix := defaultFeedback, defaultMix
+	if err := optionalNumbers("flanger", args, 1, &rate, &depth, &feedback, &mix); err != nil {
+		return err
+	}
+
+	return processed(dsp.Flanger(a.buffer(), rate, depth, feedback, mix))
+}
+
+// reverb(audio[, room_size[, damping[, mix]]])
+func reverbBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 1, 4); err != nil {
+		return err
+	}
+	a, err := audioArgument("reverb", args, 0)
+	if err != nil {
+		return err
+	}
+
+	roomSize, damping, mix := defaultRoomSize, defaultDamping, defaultReverbMix
+	if err := optionalNumbers("reverb", args, 1, &roomSize, &damping, &mix); err != nil {
+		return err
+	}
+
+	return processed(dsp.Reverb(a.buffer(), roomSize, damping, mix))
+}
+
+// checksum returns the hex SHA-256 of the WAV file that
+// save(audio, path, "pcm16") would write. Quantizing to 16 bits makes the
+// hash independent of rounding differences in the last bits of the
+// samples, so golden tests can compare it across platforms.
+func checksumBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 1, 1); err != nil {
+		return err
+	}
+	a, err := audioArgument("checksum", args, 0)
+	if err != nil {
+		return err
+	}
+
+	h := sha256.New()
+	if err := audio.EncodeWAV(h, a.buffer(), audio.Encoding{BitDepth: 16}); err != nil {
+		return newError("could not compute checksum: %s", err)
+	}
+
+	return &String{Value: hex.EncodeToString(h.Sum(nil))}
+}
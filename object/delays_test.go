@@ -0,0 +1,94 @@
+package object
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDelayBuiltins(t *testing.T) {
+	impulse := &Audio{Samples: []float32{1, 0, 0, 0}, SampleRate: 4, Channels: 1, BitDepth: 16}
+
+	tests := []struct {
+		name     string
+		args     []Object
+		expected []float32
+	}{
+		{"delay", []Object{impulse, &Float{Value: 0.25}}, []float32{0.5, 0.5, 0.25, 0.125}},
+		{"delay", []Object{impulse, &Float{Value: 0.5}, &Integer{Value: 0}, &Integer{Value: 1}}, []float32{0, 0, 1, 0}},
+		{"echo", []Object{impulse, &Array{Elements: []Object{
+			&Array{Elements: []Object{&Float{Value: 0.5}, &Float{Value: 0.5}}},
+			&Array{Elements: []Object{&Float{Value: 0.75}, &Integer{Value: -1}}},
+		}}}, []float32{1, 0, 0.5, -1}},
+		{"chorus", []Object{impulse, &Integer{Value: 0}, &Integer{Value: 0}, &Integer{Value: 0}}, []float32{1, 0, 0, 0}},
+		{"flanger", []Object{impulse, &Integer{Value: 1}, &Float{Value: 0.002}, &Float{Value: 0.5}, &Integer{Value: 0}}, []float32{1, 0, 0, 0}},
+		{"reverb", []Object{impulse, &Float{Value: 0.5}, &Float{Value: 0.5}, &Integer{Value: 0}}, []float32{1, 0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		result := GetBuiltinByName(tt.name).Fn(tt.args...)
+
+		audio, ok := result.(*Audio)
+		if !ok {
+			t.Errorf("%s: result is not Audio. got=%s", tt.name, result.Inspect())
+			continue
+		}
+
+		expected := &Audio{Samples: tt.expected, SampleRate: 4, Channels: 1, BitDepth: 16}
+		if !audio.Equal(expected) {
+			t.Errorf("%s: wrong result. want=%v, got=%v", tt.name, expected.Samples, audio.Samples)
+		}
+	}
+}
+
+func TestChecksumBuiltin(t *testing.T) {
+	a := &Audio{Samples: []float32{0.5, -0.25}, SampleRate: 8000, Channels: 1, BitDepth: 16}
+	// differs from a by less than half a 16-bit step
+	near := &Audio{Samples: []float32{0.50001, -0.25}, SampleRate: 8000, Channels: 1, BitDepth: 24}
+	other := &Audio{Samples: []float32{0.5, -0.5}, SampleRate: 8000, Channels: 1, BitDepth: 16}
+
+	sum, ok := checksumBuiltin(a).(*String)
+	if !ok || len(sum.Value) != 64 {
+		t.Fatalf("checksum is not a SHA-256 hex string. got=%v", sum)
+	}
+
+	if got := checksumBuiltin(near).(*String).Value; got != sum.Value {
+		t.Errorf("checksum should ignore differences below 16 bits. want=%s, got=%s", sum.Value, got)
+	}
+	if got := checksumBuiltin(other).(*String).Value; got == sum.Value {
+		t.Errorf("checksums of different audio should differ")
+	}
+}
+
+func TestDelayBuiltinErrors(t *testing.T) {
+	mono := &Audio{Samples: []float32{0.5, -0.25}, SampleRate: 8000, Channels: 1, BitDepth: 16}
+
+	tests := []struct {
+		name     string
+		args     []Object
+		expected string
+	}{
+		{"delay", []Object{mono}, "wrong number of arguments. got=1, want=2 to 4"},
+		{"delay", []Object{mono, &Float{Value: 0.1}, &String{Value: "a lot"}}, "third argument to `delay` must be INTEGER or FLOAT, got STRING"},
+		{"delay", []Object{mono, &Float{Value: 0.1}, &Integer{Value: 1}}, "feedback must be at least 0 and less than 1, got 1"},
+		{"echo", []Object{mono, &Integer{Value: 1}}, "second argument to `echo` must be ARRAY, got INTEGER"},
+		{"echo", []Object{mono, &Array{Elements: []Object{&Integer{Value: 1}}}}, "echo taps must be [seconds, gain] pairs, got 1"},
+		{"chorus", []Object{&Integer{Value: 1}}, "argument to `chorus` must be AUDIO, got INTEGER"},
+		{"flanger", []Object{mono, &Integer{Value: 1}, &Integer{Value: 1}}, "modulation depth must be between 0 and 0.05 seconds, got 1"},
+		{"reverb", []Object{mono, &Integer{Value: 2}}, "room size must be between 0 and 1, got 2"},
+		{"checksum", []Object{}, "wrong number of arguments. got=0, want=1"},
+	}
+
+	for _, tt := range tests {
+		result := GetBuiltinByName(tt.name).Fn(tt.args...)
+
+		err, ok := result.(*Error)
+		if !ok {
+			t.Errorf("%s: result is not Error. got=%T (%+v)", tt.name, result, result)
+			continue
+		}
+
+		if !strings.Contains(err.Message, tt.expected) {
+			t.Errorf("%s: wrong error message. want=%q, got=%q", tt.name, tt.expected, err.Message)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package object
+
+import (
+	"math"
+	"wavy/audio"
+	"wavy/dsp"
+)
+
+// Default parameters of the dynamics processors.
+const (
+	defaultCompressorAttack  = 0.01
+	defaultCompressorRelease = 0.1
+
+	defaultCeiling         = -1.0
+	defaultLookahead       = 0.005
+	defaultLimiterRelease  = 0.05
+	defaultExpanderAttack  = 0.001
+	defaultExpanderRelease = 0.1
+	defaultExpanderRangeDB = 80.0
+)
+
+// The dynamics builtins take an optional BOOLEAN after their other
+// arguments. When it is true they return [audio, envelope], where envelope
+// is an array with the gain reduction in decibels of each frame.
+
+// compress(audio, threshold_db, ratio[, attack[, release[, knee_db[, makeup_db]]]][, envelope])
+func compressBuiltin(args ...Object) Object {
+	args, envelope, err := dynamicsArguments(args, 3, 7)
+	if err != nil {
+		return err
+	}
+	a, err := audioArgument("compress", args, 0)
+	if err != nil {
+		return err
+	}
+
+	c := dsp.Compressor{Attack: defaultCompressorAttack, Release: defaultCompressorRelease}
+	if err := optionalNumbers("compress", args, 1,
+		&c.ThresholdDB, &c.Ratio, &c.Attack, &c.Release, &c.KneeDB, &c.MakeupDB); err != nil {
+		return err
+	}
+
+	b, reduction, processErr := c.Process(a.buffer())
+	return dynamicsResult(b, reduction, processErr, envelope)
+}
+
+// limit(audio[, ceiling_db[, lookahead[, release]]][, envelope])
+func limitBuiltin(args ...Object) Object {
+	args, envelope, err := dynamicsArguments(args, 1, 4)
+	if err != nil {
+		return err
+	}
+	a, err := audioArgument("limit", args, 0)
+	if err != nil {
+		return err
+	}
+
+	ceiling, lookahead, release := defaultCeiling, defaultLookahead, defaultLimiterRelease
+	if err := optionalNumbers("limit", args, 1, &ceiling, &lookahead, &release); err != nil {
+		return err
+	}
+
+	b, reduction, processErr := dsp.Limit(a.buffer(), ceiling, lookahead, release)
+	return dynamicsResult(b, reduction, processErr, envelope)
+}
+
+// gate(audio, threshold_db[, attack[, release[, range_db]]][, envelope])
+func gateBuiltin(args ...Object) Object {
+	args, envelope, err := dynamicsArguments(args, 2, 5)
+	if err != nil {
+		return err
+	}
+	a, err := audioArgument("gate", args, 0)
+	if err != nil {
+		return err
+	}
+
+	e := dsp.Expander{
+		Ratio:   math.Inf(1),
+		RangeDB: defaultExpanderRangeDB,
+		Attack:  defaultExpanderAttack,
+		Release: defaultExpanderRelease,
+	}
+	if err := optionalNumbers("gate", args, 1, &e.ThresholdDB, &e.Attack, &e.Release, &e.RangeDB); err != nil {
+		return err
+	}
+
+	b, reduction, processErr := e.Process(a.buffer())
+	return dynamicsResult(b, reduction, processErr, envelope)
+}
+
+// expand(audio, threshold_db, ratio[, attack[, release[, range_db]]][, envelope])
+func expandBuiltin(args ...Object) Object {
+	args, envelope, err := dynamicsArguments(args, 3, 6)
+	if err != nil {
+		return err
+	}
+	a, err := audioArgument("expand", args, 0)
+	if err != nil {
+		return err
+	}
+
+	e := dsp.Expander{
+		RangeDB: defaultExpanderRangeDB,
+		Attack:  defaultExpanderAttack,
+		Release: defaultExpanderRelease,
+	}
+	if err := optionalNumbers("expand", args, 1,
+		&e.ThresholdDB, &e.Ratio, &e.Attack, &e.Release, &e.RangeDB); err != nil {
+		return err
+	}
+
+	b, reduction, processErr := e.Process(a.buffer())
+	return dynamicsResult(b, reduction, processErr, envelope)
+}
+
+// dynamicsArguments splits off the trailing envelope flag, if there is
+// one, and checks the number of the other arguments.
+func dynamicsArguments(args []Object, min, max int) ([]Object, bool, *Error) {
+	envelope := false
+	if len(args) > 0 {
+		if flag, ok := args[len(args)-1].(*Boolean); ok {
+			args, envelope = args[:len(args)-1], flag.Value
+		}
+	}
+
+	if err := checkArgumentCount(args, min, max); err != nil {
+		return nil, false, err
+	}
+	return args, envelope, nil
+}
+
+// dynamicsResult converts the result of a dynamics processor to the audio,
+// or to [audio, envelope] when the envelope is asked for.
+func dynamicsResult(b *audio.Buffer, reduction []float64, err error, envelope bool) Object {
+	if err != nil {
+		return newError("%s", err)
+	}
+	if !envelope {
+		return audioFromBuffer(b)
+	}
+	return &Array{Elements: []Object{audioFromBuffer(b), floatArray(reduction)}}
+}
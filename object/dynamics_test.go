@@ -0,0 +1,95 @@
+package object
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDynamicsBuiltins(t *testing.T) {
+	// a square wave at -6.02 dBFS
+	loud := GetBuiltinByName("square").Fn(&Integer{Value: 10}, &Integer{Value: 1}, &Integer{Value: 1000}, &Float{Value: 0.5})
+
+	tests := []struct {
+		name      string
+		args      []Object
+		reduction float64 // of the last frame, in dB
+	}{
+		{"compress", []Object{loud, &Integer{Value: -20}, &Integer{Value: 4}}, 10.485},
+		{"compress", []Object{loud, &Integer{Value: -20}, &Integer{Value: 4}, &Integer{Value: 0}, &Integer{Value: 0}, &Integer{Value: 10}, &Integer{Value: 3}}, 10.485},
+		{"limit", []Object{loud, &Integer{Value: -12}}, 5.979},
+		{"limit", []Object{loud}, 0},
+		{"gate", []Object{loud, &Integer{Value: -3}, &Integer{Value: 0}, &Integer{Value: 0}, &Integer{Value: 40}}, 40},
+		{"expand", []Object{loud, &Integer{Value: 0}, &Integer{Value: 2}}, 6.021},
+	}
+
+	for _, tt := range tests {
+		plain, ok := GetBuiltinByName(tt.name).Fn(tt.args...).(*Audio)
+		if !ok {
+			t.Errorf("%s: result is not Audio", tt.name)
+			continue
+		}
+
+		args := append(append([]Object{}, tt.args...), &Boolean{Value: true})
+		result, ok := GetBuiltinByName(tt.name).Fn(args...).(*Array)
+		if !ok || len(result.Elements) != 2 {
+			t.Errorf("%s: result with envelope is not a pair. got=%v", tt.name, result)
+			continue
+		}
+
+		audio, ok := result.Elements[0].(*Audio)
+		if !ok || !audio.Equal(plain) {
+			t.Errorf("%s: audio with envelope differs from audio without", tt.name)
+		}
+
+		envelope, ok := result.Elements[1].(*Array)
+		if !ok || len(envelope.Elements) != len(plain.Samples) {
+			t.Errorf("%s: envelope should have one value per frame. got=%v", tt.name, result.Elements[1].Type())
+			continue
+		}
+
+		last := envelope.Elements[len(envelope.Elements)-1].(*Float).Value
+		if math.Abs(last-tt.reduction) > 0.01 {
+			t.Errorf("%s: wrong gain reduction. want=%g, got=%g", tt.name, tt.reduction, last)
+		}
+	}
+
+	// a false flag is the same as none
+	withFlag := compressBuiltin(loud, &Integer{Value: -20}, &Integer{Value: 4}, &Boolean{Value: false})
+	if _, ok := withFlag.(*Audio); !ok {
+		t.Errorf("compress with false flag should return audio. got=%s", withFlag.Inspect())
+	}
+}
+
+func TestDynamicsBuiltinErrors(t *testing.T) {
+	mono := &Audio{Samples: []float32{0.5, -0.25}, SampleRate: 8000, Channels: 1, BitDepth: 16}
+
+	tests := []struct {
+		name     string
+		args     []Object
+		expected string
+	}{
+		{"compress", []Object{mono, &Integer{Value: -20}}, "wrong number of arguments. got=2, want=3 to 7"},
+		{"compress", []Object{mono, &Integer{Value: -20}, &Boolean{Value: true}}, "wrong number of arguments. got=2, want=3 to 7"},
+		{"compress", []Object{mono, &Integer{Value: -20}, &Float{Value: 0.5}}, "ratio must be at least 1, got 0.5"},
+		{"compress", []Object{mono, &Integer{Value: -20}, &Integer{Value: 2}, &String{Value: "fast"}}, "fourth argument to `compress` must be INTEGER or FLOAT, got STRING"},
+		{"limit", []Object{&Integer{Value: 1}}, "argument to `limit` must be AUDIO, got INTEGER"},
+		{"limit", []Object{mono, &Integer{Value: 0}, &Integer{Value: -1}}, "lookahead must not be negative, got -1"},
+		{"gate", []Object{mono, &Integer{Value: -40}, &Integer{Value: 0}, &Integer{Value: 0}, &Integer{Value: -1}}, "range must be a finite number of decibels that is not negative, got -1"},
+		{"expand", []Object{mono, &Integer{Value: -40}, &Integer{Value: 2}, &Integer{Value: -1}}, "attack must not be negative, got -1"},
+	}
+
+	for _, tt := range tests {
+		result := GetBuiltinByName(tt.name).Fn(tt.args...)
+
+		err, ok := result.(*Error)
+		if !ok {
+			t.Errorf("%s: result is not Error. got=%T (%+v)", tt.name, result, result)
+			continue
+		}
+
+		if !strings.Contains(err.Message, tt.expected) {
+			t.Errorf("%s: wrong error message. want=%q, got=%q", tt.name, tt.expected, err.Message)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package object
+
+import (
+	"math"
+	"wavy/audio"
+	"wavy/dsp"
+)
+
+// Default parameters of the silence builtins.
+const (
+	defaultSilenceThreshold = -60.0
+	defaultMinSilence       = 0.5
+)
+
+// concat(audio, ...[, crossfade]) joins its arguments, each of which is
+// audio or an array of audio, so that the clips returned by
+// split_on_silence can be passed straight back in.
+func concatBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 1, math.MaxInt); err != nil {
+		return err
+	}
+
+	crossfade := 0.0
+	if value, ok := toFloat(args[len(args)-1]); ok && len(args) > 1 {
+		crossfade = value
+		args = args[:len(args)-1]
+	}
+
+	var buffers []*audio.Buffer
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case *Audio:
+			buffers = append(buffers, arg.buffer())
+		case *Array:
+			for _, element := range arg.Elements {
+				a, ok := element.(*Audio)
+				if !ok {
+					return newError("%s to `concat` must be AUDIO or an ARRAY of AUDIO, got an ARRAY containing %s",
+						argumentName(args, i), element.Type())
+				}
+				buffers = append(buffers, a.buffer())
+			}
+		default:
+			return newError("%s to `concat` must be AUDIO or an ARRAY of AUDIO, got %s",
+				argumentName(args, i), arg.Type())
+		}
+	}
+
+	return processed(dsp.Concat(buffers, crossfade))
+}
+
+// split_on_silence(audio, threshold_db, min_len)
+func splitOnSilenceBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 3, 3); err != nil {
+		return err
+	}
+	a, err := audioArgument("split_on_silence", args, 0)
+	if err != nil {
+		return err
+	}
+	threshold, err := numberArgument("split_on_silence", args, 1)
+	if err != nil {
+		return err
+	}
+	minLength, err := numberArgument("split_on_silence", args, 2)
+	if err != nil {
+		return err
+	}
+
+	clips, splitErr := dsp.SplitOnSilence(a.buffer(), threshold, minLength)
+	if splitErr != nil {
+		return newError("%s", splitErr)
+	}
+
+	elements := make([]Object, len(clips))
+	for i, clip := range clips {
+		elements[i] = audioFromBuffer(clip)
+	}
+	return &Array{Elements: elements}
+}
+
+// trim_silence(audio[, threshold_db])
+func trimSilenceBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 1, 2); err != nil {
+		return err
+	}
+	a, err := audioArgument("trim_silence", args, 0)
+	if err != nil {
+		return err
+	}
+
+	threshold := defaultSilenceThreshold
+	if err := optionalNumbers("trim_silence", args, 1, &threshold); err != nil {
+		return err
+	}
+
+	return audioFromBuffer(dsp.TrimSilence(a.buffer(), threshold))
+}
+
+// detect_silence(audio[, threshold_db[, min_len]]) returns an array of
+// {"start": seconds, "end": seconds} hashes, which can be used as slice
+// bounds.
+func detectSilenceBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 1, 3); err != nil {
+		return err
+	}
+	a, err := audioArgument("detect_silence", args, 0)
+	if err != nil {
+		return err
+	}
+
+	threshold, minLength := defaultSilenceThreshold, defaultMinSilence
+	if err := optionalNumbers("detect_silence", args, 1, &threshold, &minLength); err != nil {
+		return err
+	}
+
+	regions, detectErr := dsp.DetectSilence(a.buffer(), threshold, minLength)
+	if detectErr != nil {
+		return newError("%s", detectErr)
+	}
+
+	rate := float64(a.SampleRate)
+	elements := make([]Object, len(regions))
+	for i, region := range regions {
+		elements[i] = newHash(map[string]Object{
+			"start": &Float{Value: float64(region.Start) / rate},
+			"end":   &Float{Value: float64(region.End) / rate},
+		})
+	}
+	return &Array{Elements: elements}
+}
+
+// newHash returns a hash with string keys.
+func newHash(values map[string]Object) *Hash {
+	pairs := make(map[HashKey]HashPair, len(values))
+	for key, value := range values {
+		k := &String{Value: key}
+		pairs[k.HashKey()] = HashPair{Key: k, Value: value}
+	}
+	return &Hash{Pairs: pairs}
+}
@@ -0,0 +1,105 @@
+package object
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEditBuiltins(t *testing.T) {
+	a := &Audio{Samples: []float32{0.5, 0.5}, SampleRate: 2, Channels: 1, BitDepth: 16}
+	b := &Audio{Samples: []float32{-0.5}, SampleRate: 2, Channels: 1, BitDepth: 16}
+	// sound, one second of silence, sound, half a second of silence
+	gappy := &Audio{Samples: []float32{0.5, 0, 0, 0.25, 0}, SampleRate: 2, Channels: 1, BitDepth: 16}
+
+	tests := []struct {
+		name     string
+		args     []Object
+		expected []float32
+	}{
+		{"concat", []Object{a}, []float32{0.5, 0.5}},
+		{"concat", []Object{a, b, a}, []float32{0.5, 0.5, -0.5, 0.5, 0.5}},
+		{"concat", []Object{&Array{Elements: []Object{b, b}}, a}, []float32{-0.5, -0.5, 0.5, 0.5}},
+		{"concat", []Object{a, b, &Integer{Value: 0}}, []float32{0.5, 0.5, -0.5}},
+		{"concat", []Object{a, a, &Float{Value: 0.5}}, []float32{0.5, 0.70710677, 0.5}},
+		{"trim_silence", []Object{gappy}, []float32{0.5, 0, 0, 0.25}},
+		{"trim_silence", []Object{gappy, &Integer{Value: -7}}, []float32{0.5}},
+	}
+
+	for _, tt := range tests {
+		result := GetBuiltinByName(tt.name).Fn(tt.args...)
+
+		audio, ok := result.(*Audio)
+		if !ok {
+			t.Errorf("%s: result is not Audio. got=%s", tt.name, result.Inspect())
+			continue
+		}
+
+		expected := &Audio{Samples: tt.expected, SampleRate: 2, Channels: 1, BitDepth: 16}
+		if !audio.Equal(expected) {
+			t.Errorf("%s: wrong result. want=%v, got=%v", tt.name, expected.Samples, audio.Samples)
+		}
+	}
+
+	clips, ok := splitOnSilenceBuiltin(gappy, &Integer{Value: -60}, &Integer{Value: 1}).(*Array)
+	if !ok || len(clips.Elements) != 2 {
+		t.Fatalf("split_on_silence should return two clips. got=%v", clips)
+	}
+	if clip := clips.Elements[1].(*Audio); len(clip.Samples) != 2 || clip.Samples[0] != 0.25 {
+		t.Errorf("wrong second clip. got=%v", clip.Samples)
+	}
+
+	silences, ok := detectSilenceBuiltin(gappy, &Integer{Value: -60}, &Float{Value: 0.5}).(*Array)
+	if !ok || len(silences.Elements) != 2 {
+		t.Fatalf("detect_silence should find two silences. got=%v", silences)
+	}
+
+	expected := [][2]float64{{0.5, 1.5}, {2, 2.5}}
+	for i, element := range silences.Elements {
+		hash := element.(*Hash)
+		for j, key := range []string{"start", "end"} {
+			pair, ok := hash.Pairs[(&String{Value: key}).HashKey()]
+			if !ok {
+				t.Fatalf("silence %d has no %q", i, key)
+			}
+			if value := pair.Value.(*Float).Value; value != expected[i][j] {
+				t.Errorf("silence %d: wrong %s. want=%g, got=%g", i, key, expected[i][j], value)
+			}
+		}
+	}
+}
+
+func TestEditBuiltinErrors(t *testing.T) {
+	mono := &Audio{Samples: []float32{0.5, -0.25}, SampleRate: 8000, Channels: 1, BitDepth: 16}
+	stereo := &Audio{Samples: []float32{0.5, -0.25}, SampleRate: 8000, Channels: 2, BitDepth: 16}
+
+	tests := []struct {
+		name     string
+		args     []Object
+		expected string
+	}{
+		{"concat", []Object{}, "wrong number of arguments. got=0, want at least 1"},
+		{"concat", []Object{mono, &String{Value: "x"}}, "second argument to `concat` must be AUDIO or an ARRAY of AUDIO, got STRING"},
+		{"concat", []Object{&Array{Elements: []Object{&Integer{Value: 1}}}}, "argument to `concat` must be AUDIO or an ARRAY of AUDIO, got an ARRAY containing INTEGER"},
+		{"concat", []Object{mono, stereo}, "mismatched channel counts: 1 and 2"},
+		{"concat", []Object{mono, mono, &Integer{Value: -1}}, "crossfade must not be negative, got -1"},
+		{"concat", []Object{&Array{}}, "nothing to concatenate"},
+		{"split_on_silence", []Object{mono, &Integer{Value: -60}}, "wrong number of arguments. got=2, want=3"},
+		{"split_on_silence", []Object{mono, &Integer{Value: -60}, &Integer{Value: -1}}, "minimum silence length must not be negative, got -1"},
+		{"trim_silence", []Object{mono, &String{Value: "-60"}}, "second argument to `trim_silence` must be INTEGER or FLOAT, got STRING"},
+		{"detect_silence", []Object{&Array{}}, "argument to `detect_silence` must be AUDIO, got ARRAY"},
+	}
+
+	for _, tt := range tests {
+		result := GetBuiltinByName(tt.name).Fn(tt.args...)
+
+		err, ok := result.(*Error)
+		if !ok {
+			t.Errorf("%s: result is not Error. got=%T (%+v)", tt.name, result, result)
+			continue
+		}
+
+		if !strings.Contains(err.Message, tt.expected) {
+			t.Errorf("%s: wrong error message. want=%q, got=%q", tt.name, tt.expected, err.Message)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package object
+
+import (
+	"wavy/audio"
+	"wavy/dsp"
+)
+
+// The effect builtins check their arguments and call into package dsp,
+// which does the processing.
+
+func gainBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 2, 2); err != nil {
+		return err
+	}
+	a, err := audioArgument("gain", args, 0)
+	if err != nil {
+		return err
+	}
+	db, err := numberArgument("gain", args, 1)
+	if err != nil {
+		return err
+	}
+
+	return audioFromBuffer(dsp.Gain(a.buffer(), db))
+}
+
+var (
+	fadeInBuiltin  = fade("fade_in", dsp.FadeIn)
+	fadeOutBuiltin = fade("fade_out", dsp.FadeOut)
+)
+
+// fade returns the fade_in or fade_out builtin.
+func fade(name string, f func(*audio.Buffer, float64) (*audio.Buffer, error)) BuiltinFunction {
+	return func(args ...Object) Object {
+		if err := checkArgumentCount(args, 2, 2); err != nil {
+			return err
+		}
+		a, err := audioArgument(name, args, 0)
+		if err != nil {
+			return err
+		}
+		secs, err := numberArgument(name, args, 1)
+		if err != nil {
+			return err
+		}
+
+		return processed(f(a.buffer(), secs))
+	}
+}
+
+func normalizeBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 1, 2); err != nil {
+		return err
+	}
+	a, err := audioArgument("normalize", args, 0)
+	if err != nil {
+		return err
+	}
+
+	peakDB := 0.0
+	if len(args) == 2 {
+		peakDB, err = numberArgument("normalize", args, 1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return audioFromBuffer(dsp.Normalize(a.buffer(), peakDB))
+}
+
+func reverseBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 1, 1); err != nil {
+		return err
+	}
+	a, err := audioArgument("reverse", args, 0)
+	if err != nil {
+		return err
+	}
+
+	return audioFromBuffer(dsp.Reverse(a.buffer()))
+}
+
+func resampleBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 2, 2); err != nil {
+		return err
+	}
+	a, err := audioArgument("resample", args, 0)
+	if err != nil {
+		return err
+	}
+	rate, err := integerArgument("resample", args, 1)
+	if err != nil {
+		return err
+	}
+
+	return processed(dsp.Resample(a.buffer(), int(rate)))
+}
+
+func toMonoBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 1, 1); err != nil {
+		return err
+	}
+	a, err := audioArgument("to_mono", args, 0)
+	if err != nil {
+		return err
+	}
+
+	return audioFromBuffer(dsp.ToMono(a.buffer()))
+}
+
+func panBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 2, 2); err != nil {
+		return err
+	}
+	a, err := audioArgument("pan", args, 0)
+	if err != nil {
+		return err
+	}
+	position, err := numberArgument("pan", args, 1)
+	if err != nil {
+		return err
+	}
+
+	return processed(dsp.Pan(a.buffer(), position))
+}
+
+// processed converts the result of an effect that can fail.
+func processed(b *audio.Buffer, err error) Object {
+	if err != nil {
+		return newError("%s", err)
+	}
+	return audioFromBuffer(b)
+}
@@ -0,0 +1,75 @@
+package object
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEffectBuiltins(t *testing.T) {
+	mono := &Audio{Samples: []float32{0.5, -0.25}, SampleRate: 2, Channels: 1, BitDepth: 16}
+	stereo := &Audio{Samples: []float32{0.5, -0.5, 0.25, 0}, SampleRate: 2, Channels: 2, BitDepth: 16}
+
+	tests := []struct {
+		name     string
+		args     []Object
+		expected *Audio
+	}{
+		{"gain", []Object{mono, &Integer{Value: 0}}, mono},
+		{"fade_in", []Object{mono, &Float{Value: 0.5}}, &Audio{Samples: []float32{0, -0.25}, SampleRate: 2, Channels: 1, BitDepth: 16}},
+		{"fade_out", []Object{mono, &Float{Value: 0.5}}, &Audio{Samples: []float32{0.5, 0}, SampleRate: 2, Channels: 1, BitDepth: 16}},
+		{"normalize", []Object{mono}, &Audio{Samples: []float32{1, -0.5}, SampleRate: 2, Channels: 1, BitDepth: 16}},
+		{"reverse", []Object{stereo}, &Audio{Samples: []float32{0.25, 0, 0.5, -0.5}, SampleRate: 2, Channels: 2, BitDepth: 16}},
+		{"resample", []Object{mono, &Integer{Value: 2}}, mono},
+		{"to_mono", []Object{stereo}, &Audio{Samples: []float32{0, 0.125}, SampleRate: 2, Channels: 1, BitDepth: 16}},
+		{"pan", []Object{stereo, &Integer{Value: 0}}, stereo},
+	}
+
+	for _, tt := range tests {
+		result := GetBuiltinByName(tt.name).Fn(tt.args...)
+
+		audio, ok := result.(*Audio)
+		if !ok {
+			t.Errorf("%s: result is not Audio. got=%s", tt.name, result.Inspect())
+			continue
+		}
+
+		if !audio.Equal(tt.expected) {
+			t.Errorf("%s: wrong result. want=%v, got=%v", tt.name, tt.expected, audio)
+		}
+	}
+}
+
+func TestEffectBuiltinErrors(t *testing.T) {
+	mono := &Audio{Samples: []float32{0.5, -0.25}, SampleRate: 2, Channels: 1, BitDepth: 16}
+
+	tests := []struct {
+		name     string
+		args     []Object
+		expected string
+	}{
+		{"gain", []Object{mono}, "wrong number of arguments. got=1, want=2"},
+		{"gain", []Object{&Integer{Value: 1}, &Integer{Value: 1}}, "first argument to `gain` must be AUDIO, got INTEGER"},
+		{"fade_in", []Object{mono, &String{Value: "1s"}}, "second argument to `fade_in` must be INTEGER or FLOAT, got STRING"},
+		{"fade_out", []Object{mono, &Integer{Value: -1}}, "fade length must not be negative, got -1"},
+		{"normalize", []Object{}, "wrong number of arguments. got=0, want=1 or 2"},
+		{"reverse", []Object{&Array{}}, "argument to `reverse` must be AUDIO, got ARRAY"},
+		{"resample", []Object{mono, &Float{Value: 44100}}, "second argument to `resample` must be INTEGER, got FLOAT"},
+		{"resample", []Object{mono, &Integer{Value: -1}}, "sample rate must be positive, got -1"},
+		{"to_mono", []Object{mono, mono}, "wrong number of arguments. got=2, want=1"},
+		{"pan", []Object{mono, &Integer{Value: 2}}, "pan position must be between -1 and 1, got 2"},
+	}
+
+	for _, tt := range tests {
+		result := GetBuiltinByName(tt.name).Fn(tt.args...)
+
+		err, ok := result.(*Error)
+		if !ok {
+			t.Errorf("%s: result is not Error. got=%T (%+v)", tt.name, result, result)
+			continue
+		}
+
+		if !strings.Contains(err.Message, tt.expected) {
+			t.Errorf("%s: wrong error message. want=%q, got=%q", tt.name, tt.expected, err.Message)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package object
+
+import "wavy/dsp"
+
+// filter returns a builtin with the signature name(audio, freq[, q]) for a
+// filter without gain. q defaults to dsp.Butterworth.
+func filter(name string, design func(rate, freq, q float64) (dsp.Biquad, error)) BuiltinFunction {
+	return func(args ...Object) Object {
+		return applyFilter(name, args, false, func(rate, freq, q, _ float64) (dsp.Biquad, error) {
+			return design(rate, freq, q)
+		})
+	}
+}
+
+// gainFilter returns a builtin with the signature
+// name(audio, freq, gain_db[, q]) for a peaking or shelving filter.
+func gainFilter(name string, design func(rate, freq, q, gainDB float64) (dsp.Biquad, error)) BuiltinFunction {
+	return func(args ...Object) Object {
+		return applyFilter(name, args, true, design)
+	}
+}
+
+var (
+	lowpassBuiltin   = filter("lowpass", dsp.LowPass)
+	highpassBuiltin  = filter("highpass", dsp.HighPass)
+	bandpassBuiltin  = filter("bandpass", dsp.BandPass)
+	notchBuiltin     = filter("notch", dsp.Notch)
+	peakingBuiltin   = gainFilter("peaking", dsp.Peaking)
+	lowShelfBuiltin  = gainFilter("low_shelf", dsp.LowShelf)
+	highShelfBuiltin = gainFilter("high_shelf", dsp.HighShelf)
+)
+
+func applyFilter(
+	name string,
+	args []Object,
+	hasGain bool,
+	design func(rate, freq, q, gainDB float64) (dsp.Biquad, error),
+) Object {
+	required := 2
+	if hasGain {
+		required = 3
+	}
+	if err := checkArgumentCount(args, required, required+1); err != nil {
+		return err
+	}
+
+	a, err := audioArgument(name, args, 0)
+	if err != nil {
+		return err
+	}
+	freq, err := numberArgument(name, args, 1)
+	if err != nil {
+		return err
+	}
+
+	gainDB := 0.0
+	if hasGain {
+		gainDB, err = numberArgument(name, args, 2)
+		if err != nil {
+			return err
+		}
+	}
+
+	q := dsp.Butterworth
+	if len(args) > required {
+		q, err = numberArgument(name, args, required)
+		if err != nil {
+			return err
+		}
+	}
+
+	f, designErr := design(float64(a.SampleRate), freq, q, gainDB)
+	if designErr != nil {
+		return newError("%s", designErr)
+	}
+
+	return audioFromBuffer(f.Process(a.buffer()))
+}
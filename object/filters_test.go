@@ -0,0 +1,111 @@
+package object
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"wavy/dsp"
+)
+
+func TestFilterBuiltins(t *testing.T) {
+	tone := GetBuiltinByName("sine").Fn(&Integer{Value: 1000}, &Float{Value: 0.1}, &Integer{Value: 48000}).(*Audio)
+
+	tests := []struct {
+		name   string
+		args   []Object
+		design func() (dsp.Biquad, error)
+	}{
+		{
+			"lowpass",
+			[]Object{tone, &Integer{Value: 500}},
+			func() (dsp.Biquad, error) { return dsp.LowPass(48000, 500, dsp.Butterworth) },
+		},
+		{
+			"highpass",
+			[]Object{tone, &Integer{Value: 500}, &Float{Value: 2}},
+			func() (dsp.Biquad, error) { return dsp.HighPass(48000, 500, 2) },
+		},
+		{
+			"bandpass",
+			[]Object{tone, &Integer{Value: 1000}, &Integer{Value: 10}},
+			func() (dsp.Biquad, error) { return dsp.BandPass(48000, 1000, 10) },
+		},
+		{
+			"notch",
+			[]Object{tone, &Integer{Value: 1000}},
+			func() (dsp.Biquad, error) { return dsp.Notch(48000, 1000, dsp.Butterworth) },
+		},
+		{
+			"peaking",
+			[]Object{tone, &Integer{Value: 1000}, &Integer{Value: 6}, &Float{Value: 0.5}},
+			func() (dsp.Biquad, error) { return dsp.Peaking(48000, 1000, 0.5, 6) },
+		},
+		{
+			"low_shelf",
+			[]Object{tone, &Integer{Value: 200}, &Float{Value: -3.5}},
+			func() (dsp.Biquad, error) { return dsp.LowShelf(48000, 200, dsp.Butterworth, -3.5) },
+		},
+		{
+			"high_shelf",
+			[]Object{tone, &Integer{Value: 4000}, &Integer{Value: 12}, &Integer{Value: 1}},
+			func() (dsp.Biquad, error) { return dsp.HighShelf(48000, 4000, 1, 12) },
+		},
+	}
+
+	for _, tt := range tests {
+		result := GetBuiltinByName(tt.name).Fn(tt.args...)
+
+		filtered, ok := result.(*Audio)
+		if !ok {
+			t.Errorf("%s: result is not Audio. got=%s", tt.name, result.Inspect())
+			continue
+		}
+
+		f, err := tt.design()
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+
+		expected := audioFromBuffer(f.Process(tone.buffer()))
+		if !filtered.Equal(expected) {
+			t.Errorf("%s: result differs from the dsp filter", tt.name)
+		}
+	}
+
+	if math.Abs(float64(tone.Samples[12])-1) > 1e-6 {
+		t.Errorf("input was modified")
+	}
+}
+
+func TestFilterBuiltinErrors(t *testing.T) {
+	tone := &Audio{Samples: []float32{0, 1}, SampleRate: 8000, Channels: 1, BitDepth: 16}
+
+	tests := []struct {
+		name     string
+		args     []Object
+		expected string
+	}{
+		{"lowpass", []Object{tone}, "wrong number of arguments. got=1, want=2 or 3"},
+		{"peaking", []Object{tone, &Integer{Value: 100}}, "wrong number of arguments. got=2, want=3 or 4"},
+		{"highpass", []Object{&Integer{Value: 1}, &Integer{Value: 100}}, "first argument to `highpass` must be AUDIO, got INTEGER"},
+		{"bandpass", []Object{tone, &String{Value: "1k"}}, "second argument to `bandpass` must be INTEGER or FLOAT, got STRING"},
+		{"notch", []Object{tone, &Integer{Value: 100}, &Boolean{Value: true}}, "third argument to `notch` must be INTEGER or FLOAT, got BOOLEAN"},
+		{"low_shelf", []Object{tone, &Integer{Value: 100}, &Array{}}, "third argument to `low_shelf` must be INTEGER or FLOAT, got ARRAY"},
+		{"lowpass", []Object{tone, &Integer{Value: 4000}}, "filter frequency must be between 0 and 4000 Hz, got 4000"},
+		{"high_shelf", []Object{tone, &Integer{Value: 1000}, &Integer{Value: 3}, &Integer{Value: 0}}, "filter Q must be positive, got 0"},
+	}
+
+	for _, tt := range tests {
+		result := GetBuiltinByName(tt.name).Fn(tt.args...)
+
+		err, ok := result.(*Error)
+		if !ok {
+			t.Errorf("%s: result is not Error. got=%T (%+v)", tt.name, result, result)
+			continue
+		}
+
+		if !strings.Contains(err.Message, tt.expected) {
+			t.Errorf("%s: wrong error message. want=%q, got=%q", tt.name, tt.expected, err.Message)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package object
+
+import (
+	"math"
+	"math/rand"
+	"wavy/audio"
+)
+
+// Generated audio is mono and saved as 16-bit PCM unless another format is
+// requested.
+const generatorBitDepth = 16
+
+// waveform returns the value of a periodic waveform at position p in its
+// cycle, with 0 <= p < 1. Every waveform starts at zero and rises, like a
+// sine, so that the phase argument means the same thing for all of them.
+type waveform func(p float64) float64
+
+func sineWave(p float64) float64 {
+	return math.Sin(2 * math.Pi * p)
+}
+
+func squareWave(p float64) float64 {
+	if p < 0.5 {
+		return 1
+	}
+	return -1
+}
+
+func sawtoothWave(p float64) float64 {
+	p += 0.5
+	return 2*(p-math.Floor(p)) - 1
+}
+
+func triangleWave(p float64) float64 {
+	switch {
+	case p < 0.25:
+		return 4 * p
+	case p < 0.75:
+		return 2 - 4*p
+	default:
+		return 4*p - 4
+	}
+}
+
+// oscillator returns a builtin with the signature
+// name(freq, seconds, rate[, amplitude[, phase]]), where phase is in
+// radians.
+func oscillator(name string, wave waveform) BuiltinFunction {
+	return func(args ...Object) Object {
+		if err := checkArgumentCount(args, 3, 5); err != nil {
+			return err
+		}
+
+		var opt generatorArgs
+		if err := opt.parse(name, args, 1); err != nil {
+			return err
+		}
+
+		freq, err := numberArgument(name, args, 0)
+		if err != nil {
+			return err
+		}
+		if freq < 0 {
+			return newError("frequency must not be negative, got %g", freq)
+		}
+
+		phase := opt.extra / (2 * math.Pi)
+		step := freq / float64(opt.rate)
+
+		samples := make([]float32, opt.frames)
+		for i := range samples {
+			p := float64(i)*step + phase
+			samples[i] = float32(opt.amplitude * wave(p-math.Floor(p)))
+		}
+
+		return opt.audio(samples)
+	}
+}
+
+// noise returns a builtin with the signature
+// name(seconds, rate[, amplitude[, seed]]). The same seed always produces the
+// same samples.
+func noise(name string, pink bool) BuiltinFunction {
+	return func(args ...Object) Object {
+		if err := checkArgumentCount(args, 2, 4); err != nil {
+			return err
+		}
+
+		// the seed is an INTEGER, so it is left out of the generator arguments
+		var opt generatorArgs
+		if err := opt.parse(name, args[:min(len(args), 3)], 0); err != nil {
+			return err
+		}
+		var seed int64
+		if len(args) == 4 {
+			var err *Error
+			if seed, err = integerArgument(name, args, 3); err != nil {
+				return err
+			}
+		}
+
+		rng := rand.New(rand.NewSource(seed))
+		samples := make([]float32, opt.frames)
+
+		if !pink {
+			for i := range samples {
+				samples[i] = float32(opt.amplitude * (2*rng.Float64() - 1))
+			}
+			return opt.audio(samples)
+		}
+
+		// Paul Kellet's filter approximates a -3 dB/octave slope by summing
+		// one-pole filters of white noise. Its output peaks at roughly 5 times
+		// the input, which pinkScale brings back to [-1, 1].
+		const pinkScale = 0.2
+		var b0, b1, b2, b3, b4, b5, b6 float64
+		for i := range samples {
+			white := 2*rng.Float64() - 1
+			b0 = 0.99886*b0 + white*0.0555179
+			b1 = 0.99332*b1 + white*0.0750759
+			b2 = 0.96900*b2 + white*0.1538520
+			b3 = 0.86650*b3 + white*0.3104856
+			b4 = 0.55000*b4 + white*0.5329522
+			b5 = -0.7616*b5 - white*0.0168980
+			pink := b0 + b1 + b2 + b3 + b4 + b5 + b6 + white*0.5362
+			b6 = white * 0.115926
+
+			s := math.Max(-1, math.Min(1, pink*pinkScale))
+			samples[i] = float32(opt.amplitude * s)
+		}
+
+		return opt.audio(samples)
+	}
+}
+
+func silenceBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 2, 2); err != nil {
+		return err
+	}
+
+	var opt generatorArgs
+	if err := opt.parse("silence", args, 0); err != nil {
+		return err
+	}
+
+	return opt.audio(make([]float32, opt.frames))
+}
+
+// generatorArgs holds the arguments shared by all generators:
+// seconds, rate[, amplitude[, extra]], where extra is the phase of an
+// oscillator. Noise generators read their seed themselves.
+type generatorArgs struct {
+	frames    int
+	rate      int
+	amplitude float64
+	extra     float64
+}
+
+// parse reads the generator arguments from args[from] on.
+func (g *generatorArgs) parse(name string, args []Object, from int) *Error {
+	seconds, err := numberArgument(name, args, from)
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return newError("duration must be finite, got %g", seconds)
+	}
+	if seconds < 0 {
+		return newError("duration must not be negative, got %g", seconds)
+	}
+
+	rate, err := integerArgument(name, args, from+1)
+	if err != nil {
+		return err
+	}
+	if rate <= 0 {
+		return newError("sample rate must be positive, got %d", rate)
+	}
+	g.rate = int(rate)
+
+	frames := math.Round(seconds * float64(g.rate))
+	if frames > audio.MaxSamples {
+		return newError("duration of %gs at %dHz is too long", seconds, g.rate)
+	}
+	g.frames = int(frames)
+
+	g.amplitude = 1
+	return optionalNumbers(name, args, from+2, &g.amplitude, &g.extra)
+}
+
+func (g *generatorArgs) audio(samples []float32) *Audio {
+	return &Audio{
+		Samples:    samples,
+		SampleRate: g.rate,
+		Channels:   1,
+		BitDepth:   generatorBitDepth,
+	}
+}
@@ -0,0 +1,190 @@
+package object
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestOscillators(t *testing.T) {
+	// one cycle per 8 samples, so the samples are at 0, 1/8, 2/8, ... of it
+	tests := []struct {
+		name     string
+		args     []Object
+		expected []float64
+	}{
+		{
+			"sine",
+			[]Object{&Integer{Value: 1}, &Integer{Value: 1}, &Integer{Value: 8}},
+			[]float64{0, math.Sqrt2 / 2, 1, math.Sqrt2 / 2, 0, -math.Sqrt2 / 2, -1, -math.Sqrt2 / 2},
+		},
+		{
+			"square",
+			[]Object{&Integer{Value: 1}, &Integer{Value: 1}, &Integer{Value: 8}},
+			[]float64{1, 1, 1, 1, -1, -1, -1, -1},
+		},
+		{
+			"sawtooth",
+			[]Object{&Integer{Value: 1}, &Integer{Value: 1}, &Integer{Value: 8}},
+			[]float64{0, 0.25, 0.5, 0.75, -1, -0.75, -0.5, -0.25},
+		},
+		{
+			"triangle",
+			[]Object{&Integer{Value: 1}, &Integer{Value: 1}, &Integer{Value: 8}},
+			[]float64{0, 0.5, 1, 0.5, 0, -0.5, -1, -0.5},
+		},
+		{
+			"sine",
+			[]Object{&Integer{Value: 2}, &Float{Value: 0.5}, &Integer{Value: 8}, &Float{Value: 0.5}},
+			[]float64{0, 0.5, 0, -0.5},
+		},
+		{
+			"sine",
+			[]Object{&Integer{Value: 1}, &Float{Value: 0.5}, &Integer{Value: 8}, &Integer{Value: 1}, &Float{Value: math.Pi / 2}},
+			[]float64{1, math.Sqrt2 / 2, 0, -math.Sqrt2 / 2},
+		},
+		{
+			"square",
+			[]Object{&Integer{Value: 1}, &Float{Value: 0.5}, &Integer{Value: 8}, &Float{Value: 0.25}, &Float{Value: math.Pi}},
+			[]float64{-0.25, -0.25, -0.25, -0.25},
+		},
+		{
+			"silence",
+			[]Object{&Float{Value: 0.5}, &Integer{Value: 8}},
+			[]float64{0, 0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		result := GetBuiltinByName(tt.name).Fn(tt.args...)
+
+		audio, ok := result.(*Audio)
+		if !ok {
+			t.Errorf("%s: result is not Audio. got=%s", tt.name, result.Inspect())
+			continue
+		}
+
+		if audio.SampleRate != 8 || audio.Channels != 1 || audio.BitDepth != 16 {
+			t.Errorf("%s: wrong format. got=%dHz %dch %d bits", tt.name,
+				audio.SampleRate, audio.Channels, audio.BitDepth)
+		}
+
+		if len(audio.Samples) != len(tt.expected) {
+			t.Errorf("%s: wrong number of samples. want=%d, got=%d", tt.name,
+				len(tt.expected), len(audio.Samples))
+			continue
+		}
+
+		for i, want := range tt.expected {
+			if math.Abs(float64(audio.Samples[i])-want) > 1e-6 {
+				t.Errorf("%s: wrong sample %d. want=%g, got=%g", tt.name, i, want, audio.Samples[i])
+			}
+		}
+	}
+}
+
+func TestNoise(t *testing.T) {
+	for _, name := range []string{"white_noise", "pink_noise"} {
+		generate := GetBuiltinByName(name).Fn
+		args := []Object{&Integer{Value: 1}, &Integer{Value: 44100}, &Float{Value: 0.5}}
+
+		a := generate(args...).(*Audio)
+		b := generate(args...).(*Audio)
+		if !a.Equal(b) {
+			t.Errorf("%s: same seed gave different samples", name)
+		}
+
+		c := generate(append(args, &Integer{Value: 42})...).(*Audio)
+		if a.Equal(c) {
+			t.Errorf("%s: different seeds gave the same samples", name)
+		}
+
+		if len(a.Samples) != 44100 {
+			t.Errorf("%s: wrong number of samples. got=%d", name, len(a.Samples))
+		}
+
+		var sum, peak float64
+		for _, s := range a.Samples {
+			sum += float64(s)
+			peak = math.Max(peak, math.Abs(float64(s)))
+		}
+		if peak > 0.5 || peak < 0.1 {
+			t.Errorf("%s: peak outside the amplitude. got=%g", name, peak)
+		}
+		if mean := sum / float64(len(a.Samples)); math.Abs(mean) > 0.05 {
+			t.Errorf("%s: not centered on zero. mean=%g", name, mean)
+		}
+	}
+}
+
+func TestGeneratorErrors(t *testing.T) {
+	tests := []struct {
+		result   Object
+		expected string
+	}{
+		{
+			GetBuiltinByName("sine").Fn(&Integer{Value: 440}, &Integer{Value: 1}),
+			"wrong number of arguments. got=2, want=3 to 5",
+		},
+		{
+			GetBuiltinByName("sine").Fn(&String{Value: "a"}, &Integer{Value: 1}, &Integer{Value: 8000}),
+			"first argument to `sine` must be INTEGER or FLOAT, got STRING",
+		},
+		{
+			GetBuiltinByName("square").Fn(&Integer{Value: 440}, &Integer{Value: 1}, &Float{Value: 8000}),
+			"third argument to `square` must be INTEGER, got FLOAT",
+		},
+		{
+			GetBuiltinByName("triangle").Fn(&Integer{Value: 440}, &Integer{Value: 1}, &Integer{Value: 8000}, &Boolean{Value: true}),
+			"fourth argument to `triangle` must be INTEGER or FLOAT, got BOOLEAN",
+		},
+		{
+			GetBuiltinByName("sawtooth").Fn(&Integer{Value: -1}, &Integer{Value: 1}, &Integer{Value: 8000}),
+			"frequency must not be negative, got -1",
+		},
+		{
+			GetBuiltinByName("white_noise").Fn(&Integer{Value: -1}, &Integer{Value: 8000}),
+			"duration must not be negative, got -1",
+		},
+		{
+			GetBuiltinByName("silence").Fn(&Float{Value: math.NaN()}, &Integer{Value: 8000}),
+			"duration must be finite, got NaN",
+		},
+		{
+			GetBuiltinByName("sine").Fn(&Integer{Value: 440}, &Float{Value: math.Inf(1)}, &Integer{Value: 8000}),
+			"duration must be finite, got +Inf",
+		},
+		{
+			GetBuiltinByName("silence").Fn(&Float{Value: 1e19}, &Integer{Value: 8000}),
+			"duration of 1e+19s at 8000Hz is too long",
+		},
+		{
+			GetBuiltinByName("pink_noise").Fn(&Integer{Value: 1}, &Integer{Value: 0}),
+			"sample rate must be positive, got 0",
+		},
+		{
+			GetBuiltinByName("pink_noise").Fn(&Integer{Value: 1}, &Integer{Value: 8000}, &Integer{Value: 1}, &Float{Value: 1}),
+			"fourth argument to `pink_noise` must be INTEGER, got FLOAT",
+		},
+		{
+			GetBuiltinByName("silence").Fn(&Integer{Value: 1}),
+			"wrong number of arguments. got=1, want=2",
+		},
+		{
+			GetBuiltinByName("silence").Fn(&Integer{Value: 1}, &String{Value: "x"}),
+			"second argument to `silence` must be INTEGER, got STRING",
+		},
+	}
+
+	for _, tt := range tests {
+		err, ok := tt.result.(*Error)
+		if !ok {
+			t.Errorf("result is not Error. got=%T (%+v)", tt.result, tt.result)
+			continue
+		}
+
+		if !strings.Contains(err.Message, tt.expected) {
+			t.Errorf("wrong error message. want=%q, got=%q", tt.expected, err.Message)
+		}
+	}
+}
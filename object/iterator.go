@@ -0,0 +1,96 @@
+package object
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// Iterator walks the elements of an array, the characters of a string, the
+// keys of a hash or the frames of an audio buffer. It backs `for ... in`.
+type Iterator struct {
+	source Object
+	keys   []Object
+	index  int
+}
+
+func (it *Iterator) Type() ObjectType { return ITERATOR_OBJ }
+func (it *Iterator) Inspect() string {
+	return fmt.Sprintf("Iterator[%s]", it.source.Type())
+}
+
+// NewIterator returns an iterator over obj, or false if obj is not iterable.
+// Hash keys are visited in sorted order so that loops are deterministic.
+func NewIterator(obj Object) (*Iterator, bool) {
+	it := &Iterator{source: obj}
+
+	switch obj := obj.(type) {
+	case *Array, *String, *Audio:
+	case *Hash:
+		for _, pair := range obj.Pairs {
+			it.keys = append(it.keys, pair.Key)
+		}
+		sort.Slice(it.keys, func(i, j int) bool {
+			return lessHashKey(it.keys[i], it.keys[j])
+		})
+	default:
+		return nil, false
+	}
+
+	return it, true
+}
+
+// Next returns the next element, or false once the iterator is exhausted.
+func (it *Iterator) Next() (Object, bool) {
+	switch source := it.source.(type) {
+	case *Array:
+		if it.index >= len(source.Elements) {
+			return nil, false
+		}
+		it.index++
+		return source.Elements[it.index-1], true
+
+	case *String:
+		if it.index >= len(source.Value) {
+			return nil, false
+		}
+		r, size := utf8.DecodeRuneInString(source.Value[it.index:])
+		it.index += size
+		return &String{Value: string(r)}, true
+
+	case *Hash:
+		if it.index >= len(it.keys) {
+			return nil, false
+		}
+		it.index++
+		return it.keys[it.index-1], true
+
+	case *Audio:
+		if it.index >= source.Frames() {
+			return nil, false
+		}
+		it.index++
+		return source.FrameObject(it.index - 1), true
+	}
+
+	return nil, false
+}
+
+func lessHashKey(a, b Object) bool {
+	if a.Type() != b.Type() {
+		return a.Type() < b.Type()
+	}
+
+	switch a := a.(type) {
+	case *Integer:
+		return a.Value < b.(*Integer).Value
+	case *Float:
+		return a.Value < b.(*Float).Value
+	case *String:
+		return a.Value < b.(*String).Value
+	case *Boolean:
+		return !a.Value && b.(*Boolean).Value
+	}
+
+	return false
+}
@@ -0,0 +1,46 @@
+package object
+
+import (
+	"wavy/audio"
+	"wavy/dsp"
+)
+
+// meter returns a builtin that takes one AUDIO argument and returns a level
+// in decibels. measure returns a linear level.
+func meter(name string, measure func(*audio.Buffer) float64) BuiltinFunction {
+	return func(args ...Object) Object {
+		if err := checkArgumentCount(args, 1, 1); err != nil {
+			return err
+		}
+		a, err := audioArgument(name, args, 0)
+		if err != nil {
+			return err
+		}
+
+		return &Float{Value: dsp.GainToDB(measure(a.buffer()))}
+	}
+}
+
+var (
+	rmsBuiltin         = meter("rms", dsp.RMS)
+	peakBuiltin        = meter("peak", dsp.Peak)
+	crestFactorBuiltin = meter("crest_factor", dsp.CrestFactor)
+	truePeakBuiltin    = meter("true_peak", dsp.TruePeak)
+)
+
+func integratedLUFSBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 1, 1); err != nil {
+		return err
+	}
+	a, err := audioArgument("integrated_lufs", args, 0)
+	if err != nil {
+		return err
+	}
+
+	lufs, measureErr := dsp.IntegratedLoudness(a.buffer())
+	if measureErr != nil {
+		return newError("%s", measureErr)
+	}
+
+	return &Float{Value: lufs}
+}
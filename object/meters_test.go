@@ -0,0 +1,78 @@
+package object
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestMeterBuiltins(t *testing.T) {
+	// a 1 kHz sine at -20 dBFS
+	tone := GetBuiltinByName("sine").Fn(
+		&Integer{Value: 1000}, &Integer{Value: 5}, &Integer{Value: 48000}, &Float{Value: 0.1},
+	)
+	silence := GetBuiltinByName("silence").Fn(&Integer{Value: 1}, &Integer{Value: 48000})
+
+	tests := []struct {
+		name     string
+		input    Object
+		expected float64
+	}{
+		{"peak", tone, -20},
+		{"rms", tone, -23.01},
+		{"crest_factor", tone, 3.01},
+		{"integrated_lufs", tone, -23.01},
+		{"true_peak", tone, -20},
+		{"peak", silence, math.Inf(-1)},
+		{"rms", silence, math.Inf(-1)},
+		{"integrated_lufs", silence, math.Inf(-1)},
+	}
+
+	for _, tt := range tests {
+		result, ok := GetBuiltinByName(tt.name).Fn(tt.input).(*Float)
+		if !ok {
+			t.Errorf("%s: result is not Float. got=%v", tt.name, result)
+			continue
+		}
+
+		if math.IsInf(tt.expected, -1) {
+			if !math.IsInf(result.Value, -1) {
+				t.Errorf("%s of silence: want=-Inf, got=%g", tt.name, result.Value)
+			}
+			continue
+		}
+
+		if math.Abs(result.Value-tt.expected) > 0.05 {
+			t.Errorf("%s: want=%.2f, got=%.3f", tt.name, tt.expected, result.Value)
+		}
+	}
+}
+
+func TestMeterBuiltinErrors(t *testing.T) {
+	lowRate := &Audio{Samples: make([]float32, 8000), SampleRate: 2000, Channels: 1, BitDepth: 16}
+
+	tests := []struct {
+		name     string
+		args     []Object
+		expected string
+	}{
+		{"rms", []Object{}, "wrong number of arguments. got=0, want=1"},
+		{"peak", []Object{&Array{}}, "argument to `peak` must be AUDIO, got ARRAY"},
+		{"true_peak", []Object{lowRate, lowRate}, "wrong number of arguments. got=2, want=1"},
+		{"integrated_lufs", []Object{lowRate}, "cannot measure loudness at a sample rate of 2000 Hz"},
+	}
+
+	for _, tt := range tests {
+		result := GetBuiltinByName(tt.name).Fn(tt.args...)
+
+		err, ok := result.(*Error)
+		if !ok {
+			t.Errorf("%s: result is not Error. got=%T (%+v)", tt.name, result, result)
+			continue
+		}
+
+		if !strings.Contains(err.Message, tt.expected) {
+			t.Errorf("%s: wrong error message. want=%q, got=%q", tt.name, tt.expected, err.Message)
+		}
+	}
+}
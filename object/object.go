@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 	"wavy/ast"
 	"wavy/code"
 )
@@ -18,6 +21,7 @@ const (
 	ERROR_OBJ = "ERROR"
 
 	INTEGER_OBJ = "INTEGER"
+	FLOAT_OBJ   = "FLOAT"
 	BOOLEAN_OBJ = "BOOLEAN"
 	STRING_OBJ  = "STRING"
 
@@ -32,6 +36,10 @@ const (
 	COMPILED_FUNCTION_OBJ = "COMPILED_FUNCTION_OBJ"
 
 	CLOSURE_OBJ = "CLOSURE"
+
+	AUDIO_OBJ = "AUDIO"
+
+	ITERATOR_OBJ = "ITERATOR"
 )
 
 type HashKey struct {
@@ -58,6 +66,29 @@ func (i *Integer) HashKey() HashKey {
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
 
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if strings.ContainsAny(s, ".eIN") {
+		return s
+	}
+	return s + ".0"
+}
+
+// HashKey gives a float with an integral value the key of the INTEGER it
+// equals, since 1.0 == 1, which also puts -0.0 and 0.0 under one key. Other
+// floats never share a key with an INTEGER.
+func (f *Float) HashKey() HashKey {
+	if f.Value == math.Trunc(f.Value) && f.Value >= math.MinInt64 && f.Value < math.MaxInt64 {
+		return (&Integer{Value: int64(f.Value)}).HashKey()
+	}
+	return HashKey{Type: f.Type(), Value: math.Float64bits(f.Value)}
+}
+
 type Boolean struct {
 	Value bool
 }
@@ -133,6 +164,25 @@ func (s *String) HashKey() HashKey {
 	return HashKey{Type: s.Type(), Value: h.Sum64()}
 }
 
+// Len returns the number of characters, rather than bytes, in the string.
+func (s *String) Len() int { return utf8.RuneCountInString(s.Value) }
+
+// CharAt returns the i-th character of the string as a one-character string.
+func (s *String) CharAt(i int) (*String, bool) {
+	if i < 0 {
+		return nil, false
+	}
+
+	for _, r := range s.Value {
+		if i == 0 {
+			return &String{Value: string(r)}, true
+		}
+		i--
+	}
+
+	return nil, false
+}
+
 type Builtin struct {
 	Fn BuiltinFunction
 }
@@ -190,6 +240,8 @@ type CompiledFunction struct {
 	Instructions  code.Instructions
 	NumLocals     int
 	NumParameters int
+	Lines         code.LineTable
+	Name          string // empty for anonymous functions
 }
 
 func (cf *CompiledFunction) Type() ObjectType { return COMPILED_FUNCTION_OBJ }
@@ -206,3 +258,74 @@ func (c *Closure) Type() ObjectType { return CLOSURE_OBJ }
 func (c *Closure) Inspect() string {
 	return fmt.Sprintf("Closure[%p]", c)
 }
+
+// Audio is a buffer of PCM samples. Samples are interleaved, so frame i of
+// a stereo buffer is Samples[2*i] (left) and Samples[2*i+1] (right), and are
+// normalized to [-1, 1] regardless of BitDepth, which only records the
+// resolution the audio was read at or should be written with.
+type Audio struct {
+	Samples    []float32
+	SampleRate int
+	Channels   int
+	BitDepth   int
+}
+
+func (a *Audio) Type() ObjectType { return AUDIO_OBJ }
+func (a *Audio) Inspect() string {
+	return fmt.Sprintf("<audio %dHz %dch %.2fs>",
+		a.SampleRate, a.Channels, a.Duration())
+}
+
+// Frames returns the number of sample frames, i.e. the length of the buffer
+// in samples per channel.
+func (a *Audio) Frames() int {
+	if a.Channels == 0 {
+		return 0
+	}
+	return len(a.Samples) / a.Channels
+}
+
+// Duration returns the length of the buffer in seconds.
+func (a *Audio) Duration() float64 {
+	if a.SampleRate == 0 {
+		return 0
+	}
+	return float64(a.Frames()) / float64(a.SampleRate)
+}
+
+// Frame returns the samples of frame i, one per channel.
+func (a *Audio) Frame(i int) []float32 {
+	return a.Samples[i*a.Channels : (i+1)*a.Channels]
+}
+
+// FrameObject returns frame i as a FLOAT for mono audio, or as an ARRAY with
+// one FLOAT per channel otherwise.
+func (a *Audio) FrameObject(i int) Object {
+	frame := a.Frame(i)
+	if len(frame) == 1 {
+		return &Float{Value: float64(frame[0])}
+	}
+
+	elements := make([]Object, len(frame))
+	for c, sample := range frame {
+		elements[c] = &Float{Value: float64(sample)}
+	}
+
+	return &Array{Elements: elements}
+}
+
+// Equal reports whether both buffers have the same format and samples.
+func (a *Audio) Equal(other *Audio) bool {
+	if a.SampleRate != other.SampleRate || a.Channels != other.Channels ||
+		a.BitDepth != other.BitDepth || len(a.Samples) != len(other.Samples) {
+		return false
+	}
+
+	for i, s := range a.Samples {
+		if s != other.Samples[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,6 +1,9 @@
 package object
 
-import "testing"
+import (
+	"math"
+	"testing"
+)
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "Hello World"}
@@ -58,3 +61,116 @@ func TestIntegerHashKey(t *testing.T) {
 		t.Errorf("integers with twoerent content have same hash keys")
 	}
 }
+
+func TestFloatHashKey(t *testing.T) {
+	tests := []struct {
+		a, b  Hashable
+		equal bool
+	}{
+		{&Float{Value: 0.5}, &Float{Value: 0.5}, true},
+		{&Float{Value: 0.5}, &Float{Value: 0.25}, false},
+		{&Float{Value: math.Copysign(0, -1)}, &Float{Value: 0}, true},
+		{&Float{Value: 1}, &Integer{Value: 1}, true},
+		{&Float{Value: -3}, &Integer{Value: -3}, true},
+		{&Float{Value: math.Copysign(0, -1)}, &Integer{Value: 0}, true},
+		{&Float{Value: 1.5}, &Integer{Value: 1}, false},
+		{&Float{Value: math.Inf(1)}, &Float{Value: math.Inf(1)}, true},
+		{&Float{Value: math.Inf(1)}, &Integer{Value: math.MaxInt64}, false},
+		{&Float{Value: 1e300}, &Float{Value: 1e300}, true},
+	}
+
+	for _, tt := range tests {
+		if equal := tt.a.HashKey() == tt.b.HashKey(); equal != tt.equal {
+			t.Errorf("%s and %s: keys equal=%t, want %t",
+				tt.a.(Object).Inspect(), tt.b.(Object).Inspect(), equal, tt.equal)
+		}
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{0.5, "0.5"},
+		{440, "440.0"},
+		{-1.25, "-1.25"},
+		{1e21, "1e+21"},
+	}
+
+	for _, tt := range tests {
+		f := &Float{Value: tt.value}
+		if f.Inspect() != tt.expected {
+			t.Errorf("wrong Inspect() for %g. want=%q, got=%q",
+				tt.value, tt.expected, f.Inspect())
+		}
+	}
+}
+
+func TestAudioInspect(t *testing.T) {
+	audio := &Audio{
+		Samples:    make([]float32, 2*141561),
+		SampleRate: 44100,
+		Channels:   2,
+		BitDepth:   16,
+	}
+
+	if audio.Frames() != 141561 {
+		t.Errorf("wrong number of frames. want=%d, got=%d", 141561, audio.Frames())
+	}
+
+	if audio.Inspect() != "<audio 44100Hz 2ch 3.21s>" {
+		t.Errorf("wrong Inspect(). got=%q", audio.Inspect())
+	}
+}
+
+func TestAudioEqual(t *testing.T) {
+	a := &Audio{Samples: []float32{0, 0.5}, SampleRate: 8000, Channels: 1, BitDepth: 16}
+	b := &Audio{Samples: []float32{0, 0.5}, SampleRate: 8000, Channels: 1, BitDepth: 16}
+	diffSamples := &Audio{Samples: []float32{0, 0.25}, SampleRate: 8000, Channels: 1, BitDepth: 16}
+	diffRate := &Audio{Samples: []float32{0, 0.5}, SampleRate: 16000, Channels: 1, BitDepth: 16}
+	diffChannels := &Audio{Samples: []float32{0, 0.5}, SampleRate: 8000, Channels: 2, BitDepth: 16}
+
+	if !a.Equal(b) {
+		t.Errorf("audio with same content is not equal")
+	}
+
+	for _, other := range []*Audio{diffSamples, diffRate, diffChannels} {
+		if a.Equal(other) {
+			t.Errorf("audio with different content is equal: %s", other.Inspect())
+		}
+	}
+}
+
+func TestAudioCombine(t *testing.T) {
+	a := &Audio{Samples: []float32{0.5, 0.5}, SampleRate: 8000, Channels: 1, BitDepth: 16}
+	longer := &Audio{Samples: []float32{0.25, 0.25, 0.25}, SampleRate: 8000, Channels: 1, BitDepth: 24}
+	diffRate := &Audio{Samples: []float32{0, 0.5}, SampleRate: 16000, Channels: 1, BitDepth: 16}
+	diffChannels := &Audio{Samples: []float32{0, 0.5}, SampleRate: 8000, Channels: 2, BitDepth: 16}
+
+	add := func(x, y float32) float32 { return x + y }
+
+	mixed, err := a.Combine(longer, add)
+	if err != nil {
+		t.Fatalf("Combine returned error: %s", err)
+	}
+	expected := &Audio{Samples: []float32{0.75, 0.75, 0.25}, SampleRate: 8000, Channels: 1, BitDepth: 24}
+	if !mixed.Equal(expected) {
+		t.Errorf("wrong mix. want=%v, got=%v", expected, mixed)
+	}
+
+	tests := []struct {
+		other    *Audio
+		expected string
+	}{
+		{diffRate, "mismatched sample rates: 8000Hz and 16000Hz"},
+		{diffChannels, "mismatched channel counts: 1 and 2"},
+	}
+
+	for _, tt := range tests {
+		_, err := a.Combine(tt.other, add)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("wrong error. want=%q, got=%v", tt.expected, err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package object
+
+import (
+	"fmt"
+	"math"
+)
+
+// Slice returns the part of an ARRAY, STRING or AUDIO value from start up to
+// but not including end. A NULL bound is omitted: it stands for the start or
+// the end of the value. Negative bounds count from the end, and bounds past
+// either end are clamped, so slicing never fails because of the values of
+// its bounds.
+//
+// Arrays are indexed by element, strings by character and audio by frame.
+// Audio also takes FLOAT bounds, which are in seconds.
+func Slice(left, start, end Object) (Object, error) {
+	switch left := left.(type) {
+	case *Array:
+		lo, hi, err := sliceRange(len(left.Elements), start, end, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		elements := make([]Object, hi-lo)
+		copy(elements, left.Elements[lo:hi])
+		return &Array{Elements: elements}, nil
+
+	case *String:
+		runes := []rune(left.Value)
+		lo, hi, err := sliceRange(len(runes), start, end, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return &String{Value: string(runes[lo:hi])}, nil
+
+	case *Audio:
+		lo, hi, err := sliceRange(left.Frames(), start, end, left.SampleRate)
+		if err != nil {
+			return nil, err
+		}
+
+		samples := make([]float32, (hi-lo)*left.Channels)
+		copy(samples, left.Samples[lo*left.Channels:hi*left.Channels])
+		return &Audio{
+			Samples:    samples,
+			SampleRate: left.SampleRate,
+			Channels:   left.Channels,
+			BitDepth:   left.BitDepth,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("slice operator not supported: %s", left.Type())
+	}
+}
+
+// sliceRange converts slice bounds to indexes with 0 <= lo <= hi <= length.
+// FLOAT bounds are only allowed when rate, the number of indexes per second,
+// is not 0.
+func sliceRange(length int, start, end Object, rate int) (int, int, error) {
+	lo, err := sliceBound(length, start, 0, rate)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	hi, err := sliceBound(length, end, length, rate)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return lo, max(lo, hi), nil
+}
+
+func sliceBound(length int, bound Object, omitted int, rate int) (int, error) {
+	var index float64
+
+	switch bound := bound.(type) {
+	case *Null:
+		return omitted, nil
+	case *Integer:
+		index = float64(bound.Value)
+	case *Float:
+		if rate == 0 {
+			return 0, fmt.Errorf("slice bounds must be INTEGER, got FLOAT")
+		}
+		if math.IsNaN(bound.Value) {
+			return 0, fmt.Errorf("slice bound is NaN")
+		}
+		index = math.Round(bound.Value * float64(rate))
+	default:
+		if rate == 0 {
+			return 0, fmt.Errorf("slice bounds must be INTEGER, got %s", bound.Type())
+		}
+		return 0, fmt.Errorf("slice bounds must be INTEGER or FLOAT, got %s", bound.Type())
+	}
+
+	if index < 0 {
+		index += float64(length)
+	}
+
+	return int(math.Max(0, math.Min(float64(length), index))), nil
+}
@@ -0,0 +1,211 @@
+package object
+
+import (
+	"math/cmplx"
+	"wavy/dsp"
+)
+
+// signalArgument returns argument i, which is mono AUDIO or an ARRAY of
+// numbers, as samples. rate is the sample rate of audio and 0 for arrays.
+func signalArgument(name string, args []Object, i int) ([]float64, int, *Error) {
+	switch arg := args[i].(type) {
+	case *Audio:
+		if arg.Channels != 1 {
+			return nil, 0, newError("`%s` needs mono audio, got %d channels; use to_mono first",
+				name, arg.Channels)
+		}
+
+		samples := make([]float64, len(arg.Samples))
+		for j, s := range arg.Samples {
+			samples[j] = float64(s)
+		}
+		return samples, arg.SampleRate, nil
+
+	case *Array:
+		samples, err := numberElements(name, arg)
+		return samples, 0, err
+
+	default:
+		return nil, 0, newError("%s to `%s` must be AUDIO or ARRAY, got %s",
+			argumentName(args, i), name, args[i].Type())
+	}
+}
+
+func numberElements(name string, arr *Array) ([]float64, *Error) {
+	values := make([]float64, len(arr.Elements))
+	for i, el := range arr.Elements {
+		value, ok := toFloat(el)
+		if !ok {
+			return nil, newError("elements of an array passed to `%s` must be INTEGER or FLOAT, got %s",
+				name, el.Type())
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+func floatArray(values []float64) *Array {
+	elements := make([]Object, len(values))
+	for i, v := range values {
+		elements[i] = &Float{Value: v}
+	}
+
+	return &Array{Elements: elements}
+}
+
+func fftBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 1, 1); err != nil {
+		return err
+	}
+	samples, _, err := signalArgument("fft", args, 0)
+	if err != nil {
+		return err
+	}
+
+	x := make([]complex128, len(samples))
+	for i, s := range samples {
+		x[i] = complex(s, 0)
+	}
+
+	spectrum := dsp.FFT(x)
+	magnitudes := make([]float64, len(spectrum))
+	phases := make([]float64, len(spectrum))
+	for k, v := range spectrum {
+		magnitudes[k] = cmplx.Abs(v)
+		phases[k] = cmplx.Phase(v)
+	}
+
+	return &Array{Elements: []Object{floatArray(magnitudes), floatArray(phases)}}
+}
+
+func ifftBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 2, 3); err != nil {
+		return err
+	}
+
+	parts := make([][]float64, 2)
+	for i := range parts {
+		arr, ok := args[i].(*Array)
+		if !ok {
+			return newError("%s to `ifft` must be ARRAY, got %s",
+				argumentName(args, i), args[i].Type())
+		}
+
+		var err *Error
+		parts[i], err = numberElements("ifft", arr)
+		if err != nil {
+			return err
+		}
+	}
+
+	magnitudes, phases := parts[0], parts[1]
+	if len(magnitudes) != len(phases) {
+		return newError("`ifft` needs as many phases as magnitudes, got %d magnitudes and %d phases",
+			len(magnitudes), len(phases))
+	}
+
+	x := make([]complex128, len(magnitudes))
+	for k := range x {
+		x[k] = cmplx.Rect(magnitudes[k], phases[k])
+	}
+
+	signal := dsp.IFFT(x)
+
+	if len(args) == 2 {
+		values := make([]float64, len(signal))
+		for i, v := range signal {
+			values[i] = real(v)
+		}
+		return floatArray(values)
+	}
+
+	rate, err := integerArgument("ifft", args, 2)
+	if err != nil {
+		return err
+	}
+	if rate <= 0 {
+		return newError("sample rate must be positive, got %d", rate)
+	}
+
+	samples := make([]float32, len(signal))
+	for i, v := range signal {
+		samples[i] = float32(real(v))
+	}
+
+	return &Audio{Samples: samples, SampleRate: int(rate), Channels: 1, BitDepth: generatorBitDepth}
+}
+
+func spectrogramBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 3, 4); err != nil {
+		return err
+	}
+	samples, _, err := signalArgument("spectrogram", args, 0)
+	if err != nil {
+		return err
+	}
+	size, err := integerArgument("spectrogram", args, 1)
+	if err != nil {
+		return err
+	}
+	hop, err := integerArgument("spectrogram", args, 2)
+	if err != nil {
+		return err
+	}
+
+	window := dsp.Hann
+	if len(args) == 4 {
+		name, ok := args[3].(*String)
+		if !ok {
+			return newError("fourth argument to `spectrogram` must be STRING, got %s",
+				args[3].Type())
+		}
+
+		var windowErr error
+		window, windowErr = dsp.WindowByName(name.Value)
+		if windowErr != nil {
+			return newError("%s", windowErr)
+		}
+	}
+
+	frames, spectrogramErr := dsp.Spectrogram(samples, int(size), int(hop), window)
+	if spectrogramErr != nil {
+		return newError("%s", spectrogramErr)
+	}
+
+	elements := make([]Object, len(frames))
+	for i, frame := range frames {
+		elements[i] = floatArray(frame)
+	}
+
+	return &Array{Elements: elements}
+}
+
+func peakFrequencyBuiltin(args ...Object) Object {
+	if err := checkArgumentCount(args, 1, 2); err != nil {
+		return err
+	}
+	samples, rate, err := signalArgument("peak_frequency", args, 0)
+	if err != nil {
+		return err
+	}
+
+	_, isAudio := args[0].(*Audio)
+	switch {
+	case isAudio && len(args) == 2:
+		return newError("`peak_frequency` takes no sample rate for AUDIO, which has its own")
+	case !isAudio && len(args) == 1:
+		return newError("`peak_frequency` needs a sample rate for an ARRAY")
+	case !isAudio:
+		r, err := integerArgument("peak_frequency", args, 1)
+		if err != nil {
+			return err
+		}
+		if r <= 0 {
+			return newError("sample rate must be positive, got %d", r)
+		}
+		rate = int(r)
+	}
+
+	return &Float{Value: dsp.PeakFrequency(samples, float64(rate))}
+}
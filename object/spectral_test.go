@@ -0,0 +1,120 @@
+package object
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestFFTBuiltins(t *testing.T) {
+	signal := &Array{Elements: []Object{
+		&Integer{Value: 1}, &Float{Value: 0.5}, &Integer{Value: 0}, &Float{Value: -0.5}, &Integer{Value: 2},
+	}}
+
+	result, ok := GetBuiltinByName("fft").Fn(signal).(*Array)
+	if !ok || len(result.Elements) != 2 {
+		t.Fatalf("fft did not return [magnitudes, phases]. got=%v", result)
+	}
+
+	magnitudes := result.Elements[0].(*Array)
+	phases := result.Elements[1].(*Array)
+	if len(magnitudes.Elements) != 5 || len(phases.Elements) != 5 {
+		t.Fatalf("wrong number of bins. got=%d and %d", len(magnitudes.Elements), len(phases.Elements))
+	}
+
+	// the DC bin is the sum of the samples
+	if dc := magnitudes.Elements[0].(*Float).Value; math.Abs(dc-3) > 1e-12 {
+		t.Errorf("wrong DC magnitude. want=3, got=%g", dc)
+	}
+
+	back, ok := GetBuiltinByName("ifft").Fn(magnitudes, phases).(*Array)
+	if !ok {
+		t.Fatalf("ifft did not return an array")
+	}
+	for i, el := range back.Elements {
+		want, _ := toFloat(signal.Elements[i])
+		if got := el.(*Float).Value; math.Abs(got-want) > 1e-12 {
+			t.Errorf("ifft(fft(x)) differs at %d. want=%g, got=%g", i, want, got)
+		}
+	}
+
+	audio, ok := GetBuiltinByName("ifft").Fn(magnitudes, phases, &Integer{Value: 8000}).(*Audio)
+	if !ok {
+		t.Fatalf("ifft with a rate did not return audio")
+	}
+	if audio.SampleRate != 8000 || audio.Channels != 1 || len(audio.Samples) != 5 {
+		t.Errorf("wrong audio from ifft: %s", audio.Inspect())
+	}
+	if math.Abs(float64(audio.Samples[4])-2) > 1e-6 {
+		t.Errorf("wrong sample. want=2, got=%g", audio.Samples[4])
+	}
+}
+
+func TestSpectralBuiltins(t *testing.T) {
+	tone := GetBuiltinByName("sine").Fn(&Integer{Value: 1000}, &Integer{Value: 1}, &Integer{Value: 8000}).(*Audio)
+
+	frames, ok := GetBuiltinByName("spectrogram").Fn(tone, &Integer{Value: 64}, &Integer{Value: 32}, &String{Value: "blackman"}).(*Array)
+	if !ok {
+		t.Fatalf("spectrogram did not return an array")
+	}
+	if len(frames.Elements) != (8000-64)/32+1 {
+		t.Errorf("wrong number of frames. got=%d", len(frames.Elements))
+	}
+	if bins := frames.Elements[0].(*Array); len(bins.Elements) != 33 {
+		t.Errorf("wrong number of bins. got=%d", len(bins.Elements))
+	}
+
+	peak, ok := GetBuiltinByName("peak_frequency").Fn(tone).(*Float)
+	if !ok || math.Abs(peak.Value-1000) > 0.1 {
+		t.Errorf("wrong peak frequency of audio. got=%v", peak)
+	}
+
+	samples := &Array{}
+	for _, s := range tone.Samples[:800] {
+		samples.Elements = append(samples.Elements, &Float{Value: float64(s)})
+	}
+	peak, ok = GetBuiltinByName("peak_frequency").Fn(samples, &Integer{Value: 8000}).(*Float)
+	if !ok || math.Abs(peak.Value-1000) > 1 {
+		t.Errorf("wrong peak frequency of array. got=%v", peak)
+	}
+}
+
+func TestSpectralBuiltinErrors(t *testing.T) {
+	mono := &Audio{Samples: []float32{0, 1, 0, -1}, SampleRate: 4, Channels: 1, BitDepth: 16}
+	stereo := &Audio{Samples: []float32{0, 1, 0, -1}, SampleRate: 4, Channels: 2, BitDepth: 16}
+	numbers := &Array{Elements: []Object{&Integer{Value: 1}, &Integer{Value: 2}}}
+
+	tests := []struct {
+		name     string
+		args     []Object
+		expected string
+	}{
+		{"fft", []Object{stereo}, "`fft` needs mono audio, got 2 channels; use to_mono first"},
+		{"fft", []Object{&String{Value: "x"}}, "argument to `fft` must be AUDIO or ARRAY, got STRING"},
+		{"fft", []Object{&Array{Elements: []Object{&Boolean{Value: true}}}}, "elements of an array passed to `fft` must be INTEGER or FLOAT, got BOOLEAN"},
+		{"ifft", []Object{numbers}, "wrong number of arguments. got=1, want=2 or 3"},
+		{"ifft", []Object{numbers, mono}, "second argument to `ifft` must be ARRAY, got AUDIO"},
+		{"ifft", []Object{numbers, &Array{}}, "`ifft` needs as many phases as magnitudes, got 2 magnitudes and 0 phases"},
+		{"ifft", []Object{numbers, numbers, &Integer{Value: 0}}, "sample rate must be positive, got 0"},
+		{"spectrogram", []Object{mono, &Integer{Value: 2}, &Integer{Value: 0}}, "hop must be positive, got 0"},
+		{"spectrogram", []Object{mono, &Float{Value: 2}, &Integer{Value: 1}}, "second argument to `spectrogram` must be INTEGER, got FLOAT"},
+		{"spectrogram", []Object{mono, &Integer{Value: 2}, &Integer{Value: 1}, &String{Value: "flat"}}, `unknown window "flat"`},
+		{"peak_frequency", []Object{numbers}, "`peak_frequency` needs a sample rate for an ARRAY"},
+		{"peak_frequency", []Object{mono, &Integer{Value: 4}}, "`peak_frequency` takes no sample rate for AUDIO, which has its own"},
+		{"peak_frequency", []Object{numbers, &Integer{Value: -4}}, "sample rate must be positive, got -4"},
+	}
+
+	for _, tt := range tests {
+		result := GetBuiltinByName(tt.name).Fn(tt.args...)
+
+		err, ok := result.(*Error)
+		if !ok {
+			t.Errorf("%s: result is not Error. got=%T (%+v)", tt.name, result, result)
+			continue
+		}
+
+		if !strings.Contains(err.Message, tt.expected) {
+			t.Errorf("%s: wrong error message. want=%q, got=%q", tt.name, tt.expected, err.Message)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"wavy/ast"
+	"wavy/compiler"
+	"wavy/lexer"
+	"wavy/object"
+	"wavy/parser"
+	"wavy/vm"
+)
+
+const (
+	PROMPT          = ">> "
+	CONTINUE_PROMPT = ".. "
+)
+
+const help = `meta-commands:
+  :ast       toggle printing the AST of each entry
+  :bytecode  toggle printing the bytecode of each entry
+  :reset     forget all bindings
+  :help      show this message
+  :quit      leave the repl
+`
+
+// session holds the state that survives between entries: the symbol table
+// and constants the compiler appends to, and the globals the VM writes to.
+type session struct {
+	symbolTable *compiler.SymbolTable
+	constants   []object.Object
+	globals     []object.Object
+
+	showAST      bool
+	showBytecode bool
+}
+
+func newSession() *session {
+	symbolTable := compiler.NewSymbolTable()
+	for i, v := range object.Builtins {
+		symbolTable.DefineBuiltin(i, v.Name)
+	}
+
+	return &session{
+		symbolTable: symbolTable,
+		constants:   []object.Object{},
+		globals:     make([]object.Object, vm.GlobalsSize),
+	}
+}
+
+func Start(in io.Reader, out io.Writer) {
+	scanner := bufio.NewScanner(in)
+	s := newSession()
+
+	for {
+		input, ok := readEntry(scanner, out)
+		if !ok {
+			return
+		}
+
+		trimmed := strings.TrimSpace(input)
+		if trimmed == "" {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, ":") {
+			if !s.meta(trimmed, out) {
+				return
+			}
+			continue
+		}
+
+		s.eval(input, out)
+	}
+}
+
+// readEntry reads lines until the brackets in the entry are balanced, so that
+// function bodies and hash literals can span several lines.
+func readEntry(scanner *bufio.Scanner, out io.Writer) (string, bool) {
+	var lines []string
+
+	fmt.Fprint(out, PROMPT)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		entry := strings.Join(lines, "\n")
+
+		if bracketDepth(entry) <= 0 {
+			return entry, true
+		}
+
+		fmt.Fprint(out, CONTINUE_PROMPT)
+	}
+
+	if len(lines) > 0 {
+		return strings.Join(lines, "\n"), true
+	}
+
+	return "", false
+}
+
+// bracketDepth counts the brackets left open in input, ignoring those in
+// strings and comments.
+func bracketDepth(input string) int {
+	depth := 0
+	inString := false
+
+	for i := 0; i < len(input); i++ {
+		switch ch := input[i]; {
+		case ch == '"':
+			inString = !inString
+		case inString && ch == '\\':
+			i++ // skip the escaped character
+		case inString:
+		case strings.HasPrefix(input[i:], "//"):
+			end := strings.IndexByte(input[i:], '\n')
+			if end < 0 {
+				return depth
+			}
+			i += end
+		case strings.HasPrefix(input[i:], "/*"):
+			end := strings.Index(input[i+2:], "*/")
+			if end < 0 {
+				// keep reading until the comment is closed
+				return depth + 1
+			}
+			i += end + 3
+		case ch == '{' || ch == '(' || ch == '[':
+			depth++
+		case ch == '}' || ch == ')' || ch == ']':
+			depth--
+		}
+	}
+
+	return depth
+}
+
+func (s *session) meta(command string, out io.Writer) bool {
+	switch command {
+	case ":ast":
+		s.showAST = !s.showAST
+		fmt.Fprintf(out, "ast printing %s\n", onOff(s.showAST))
+	case ":bytecode":
+		s.showBytecode = !s.showBytecode
+		fmt.Fprintf(out, "bytecode printing %s\n", onOff(s.showBytecode))
+	case ":reset":
+		showAST, showBytecode := s.showAST, s.showBytecode
+		*s = *newSession()
+		s.showAST, s.showBytecode = showAST, showBytecode
+		fmt.Fprintln(out, "state cleared")
+	case ":help":
+		fmt.Fprint(out, help)
+	case ":quit", ":q":
+		return false
+	default:
+		fmt.Fprintf(out, "unknown meta-command %s\n", command)
+		fmt.Fprint(out, help)
+	}
+
+	return true
+}
+
+func (s *session) eval(input string, out io.Writer) {
+	l := lexer.New(input)
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if len(l.Errors()) != 0 {
+		printErrors(out, "lexer", l.Errors())
+		return
+	}
+	if len(p.Errors()) != 0 {
+		printErrors(out, "parser", p.Errors())
+		return
+	}
+
+	if s.showAST {
+		for _, stmt := range program.Statements {
+			fmt.Fprintln(out, stmt.String())
+		}
+	}
+
+	comp := compiler.NewWithState(s.symbolTable, s.constants)
+	err := comp.Compile(program)
+	if err != nil {
+		fmt.Fprintf(out, "compiler error: %s\n", err)
+		return
+	}
+
+	code := comp.Bytecode()
+	s.constants = code.Constants
+
+	if s.showBytecode {
+		fmt.Fprint(out, code.Instructions.String())
+	}
+
+	machine := vm.NewWithGlobalsStore(code, s.globals)
+	err = machine.Run()
+	if err != nil {
+		var runtimeErr *vm.RuntimeError
+		if errors.As(err, &runtimeErr) && len(runtimeErr.Frames) > 1 {
+			fmt.Fprint(out, runtimeErr.Traceback())
+		}
+		fmt.Fprintf(out, "runtime error: %s\n", err)
+		return
+	}
+
+	if !endsWithExpression(program) {
+		return
+	}
+
+	if result := machine.LastPoppedStackElem(); result != nil {
+		fmt.Fprintln(out, result.Inspect())
+	}
+}
+
+func endsWithExpression(program *ast.Program) bool {
+	if len(program.Statements) == 0 {
+		return false
+	}
+
+	_, ok := program.Statements[len(program.Statements)-1].(*ast.ExpressionStatement)
+	return ok
+}
+
+func printErrors(out io.Writer, phase string, errors []string) {
+	fmt.Fprintf(out, "%s has %d error(s):\n", phase, len(errors))
+	for _, msg := range errors {
+		fmt.Fprintf(out, "\t%s\n", msg)
+	}
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
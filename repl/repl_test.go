@@ -0,0 +1,86 @@
+package repl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStartKeepsState(t *testing.T) {
+	input := `let a = 5;
+let add = fn(x, y) {
+  x + y
+};
+add(a, 10)
+:reset
+a
+`
+
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	expected := []string{
+		PROMPT + PROMPT + CONTINUE_PROMPT + CONTINUE_PROMPT + PROMPT + "15",
+		PROMPT + "state cleared",
+		PROMPT + "compiler error: undefined variable a at line 1, position 1",
+		PROMPT,
+	}
+
+	if out.String() != strings.Join(expected, "\n") {
+		t.Errorf("wrong output.\nwant=%q\ngot =%q",
+			strings.Join(expected, "\n"), out.String())
+	}
+}
+
+func TestStartMetaCommands(t *testing.T) {
+	input := `:ast
+1 + 2 * 3
+:ast
+:bytecode
+1
+`
+
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	expected := []string{
+		PROMPT + "ast printing on",
+		PROMPT + "(1 + (2 * 3))",
+		"7",
+		PROMPT + "ast printing off",
+		PROMPT + "bytecode printing on",
+		PROMPT + "0000 OpConstant 3",
+		"0003 OpPop",
+		"1",
+		PROMPT,
+	}
+
+	if out.String() != strings.Join(expected, "\n") {
+		t.Errorf("wrong output.\nwant=%q\ngot =%q",
+			strings.Join(expected, "\n"), out.String())
+	}
+}
+
+func TestBracketDepth(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{`let a = 1;`, 0},
+		{`let f = fn(x) {`, 1},
+		{`let h = {"{": [1, 2`, 2},
+		{`}`, -1},
+		{`let f = fn(x) { // {`, 1},
+		{"let f = fn(x) { // {\n}", 0},
+		{`/* ( */ [`, 1},
+		{`/* unclosed`, 1},
+		{`let s = "\" {";`, 0},
+	}
+
+	for _, tt := range tests {
+		if depth := bracketDepth(tt.input); depth != tt.expected {
+			t.Errorf("bracketDepth(%q) wrong. want=%d, got=%d",
+				tt.input, tt.expected, depth)
+		}
+	}
+}
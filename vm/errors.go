@@ -0,0 +1,109 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+	"wavy/code"
+	"wavy/token"
+)
+
+// RuntimeError is returned by Run when an instruction fails. Pos is the
+// source position the failing instruction was compiled from, if known, and
+// Frames is the call stack at the time of the failure, innermost first.
+type RuntimeError struct {
+	Err    error
+	Pos    token.Position
+	Frames []TraceFrame
+}
+
+// TraceFrame describes one active call: the function's name, the source
+// position and the offset of the instruction it was executing.
+type TraceFrame struct {
+	Function string
+	Pos      token.Position
+	Offset   int
+}
+
+func (f TraceFrame) String() string {
+	if !f.Pos.IsValid() {
+		return fmt.Sprintf("%s, offset %04d", f.Function, f.Offset)
+	}
+	return fmt.Sprintf("%s at %s, offset %04d", f.Function, f.Pos, f.Offset)
+}
+
+func (e *RuntimeError) Error() string {
+	if !e.Pos.IsValid() {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s at %s", e.Err, e.Pos)
+}
+
+func (e *RuntimeError) Unwrap() error { return e.Err }
+
+// Traceback lists the frames outermost first, so that the failing call is
+// printed last, next to the error message. Runs of identical frames, as left
+// by deep recursion, are collapsed into one line.
+func (e *RuntimeError) Traceback() string {
+	var out strings.Builder
+
+	out.WriteString("traceback (most recent call last):\n")
+	for i := len(e.Frames) - 1; i >= 0; {
+		frame := e.Frames[i]
+		fmt.Fprintf(&out, "  in %s\n", frame)
+
+		repeated := 0
+		for i--; i >= 0 && e.Frames[i] == frame; i-- {
+			repeated++
+		}
+		if repeated > 0 {
+			fmt.Fprintf(&out, "  ... repeated %d more time(s)\n", repeated)
+		}
+	}
+
+	return out.String()
+}
+
+// locate wraps err with the source position of the failing instruction and
+// unwinds the active frames into a trace.
+func (vm *VM) locate(err error) error {
+	frames := make([]TraceFrame, 0, vm.framesIndex)
+	for i := vm.framesIndex - 1; i >= 0; i-- {
+		frame := vm.frames[i]
+		fn := frame.cl.Fn
+
+		offset := instructionStart(fn.Instructions, frame.ip)
+		pos, _ := fn.Lines.Lookup(offset)
+
+		name := fn.Name
+		switch {
+		case i == 0:
+			name = "<main>"
+		case name == "":
+			name = "<anonymous>"
+		}
+
+		frames = append(frames, TraceFrame{Function: name, Pos: pos, Offset: offset})
+	}
+
+	return &RuntimeError{Err: err, Pos: frames[0].Pos, Frames: frames}
+}
+
+// instructionStart returns the offset of the instruction whose opcode or
+// operands are at offset. The frame's ip may have moved past the opcode
+// while reading operands.
+func instructionStart(ins code.Instructions, offset int) int {
+	start := 0
+	for i := 0; i < len(ins) && i <= offset; {
+		start = i
+
+		def, err := code.Lookup(ins[i])
+		if err != nil {
+			return offset
+		}
+
+		_, read := code.ReadOperands(def, ins[i+1:])
+		i += 1 + read
+	}
+
+	return start
+}
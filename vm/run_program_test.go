@@ -79,3 +79,50 @@ func TestVMOutput(t *testing.T) {
 	output_file.WriteString(vm.LastPoppedStackElem().Inspect())
 
 }
+
+// TestSamples runs each program in samples and compares the inspected value
+// of its last expression with the golden file of the same name in
+// samples/expected_outputs. The audio samples end with checksums, so a
+// change to an effect that alters its output shows up here.
+func TestSamples(t *testing.T) {
+	files, err := filepath.Glob("samples/*.vy")
+	if err != nil {
+		t.Fatalf("error listing samples: %v", err)
+	}
+
+	for _, file := range files {
+		expected, err := os.ReadFile(filepath.Join("samples", "expected_outputs", filepath.Base(file)+".out"))
+		if err != nil {
+			t.Errorf("%s: error reading expected output: %v", file, err)
+			continue
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("error reading file: %v", err)
+		}
+
+		p := parser.New(lexer.New(string(content)))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Errorf("%s: parser errors: %v", file, p.Errors())
+			continue
+		}
+
+		comp := compiler.New()
+		if err := comp.Compile(program); err != nil {
+			t.Errorf("%s: compiler error: %s", file, err)
+			continue
+		}
+
+		vm := New(comp.Bytecode())
+		if err := vm.Run(); err != nil {
+			t.Errorf("%s: vm error: %s", file, err)
+			continue
+		}
+
+		if got := vm.LastPoppedStackElem().Inspect(); got != string(expected) {
+			t.Errorf("%s: wrong output.\nwant=%s\ngot= %s", file, expected, got)
+		}
+	}
+}
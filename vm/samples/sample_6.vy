@@ -0,0 +1,7 @@
+let rate = 8000;
+let pluck = fade_out(sine(440, 0.25, rate, 0.5), 0.25) + silence(1, rate);
+
+let slapback = delay(pluck, 0.125, 0.3, 0.4);
+let canyon = echo(pluck, [[0.2, 0.5], [0.4, 0.25], [0.6, 0.125]]);
+
+[checksum(slapback), checksum(canyon)]
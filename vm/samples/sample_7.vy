@@ -0,0 +1,9 @@
+let rate = 8000;
+let chord = sine(220, 1, rate, 0.2) + sine(277.18, 1, rate, 0.2) + triangle(329.63, 1, rate, 0.2);
+let stereo = pan(chord, -0.3);
+
+let wide = chorus(stereo);
+let jet = flanger(chord, 0.5, 0.003, 0.7);
+let hall = reverb(fade_out(stereo, 0.5) + pan(silence(1.5, rate), 0), 0.8, 0.3, 0.4);
+
+[checksum(wide), checksum(jet), checksum(hall)]
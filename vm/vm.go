@@ -2,6 +2,8 @@ package vm
 
 import (
 	"fmt"
+	"io"
+	"math"
 	"wavy/code"
 	"wavy/compiler"
 	"wavy/object"
@@ -28,7 +30,10 @@ type VM struct {
 }
 
 func New(bytecode *compiler.Bytecode) *VM {
-	mainFn := &object.CompiledFunction{Instructions: bytecode.Instructions}
+	mainFn := &object.CompiledFunction{
+		Instructions: bytecode.Instructions,
+		Lines:        bytecode.Lines,
+	}
 	mainClosure := &object.Closure{Fn: mainFn}
 	mainFrame := NewFrame(mainClosure, 0)
 
@@ -48,6 +53,16 @@ func New(bytecode *compiler.Bytecode) *VM {
 	}
 }
 
+// Load creates a VM for bytecode read from a .vyc file.
+func Load(r io.Reader) (*VM, error) {
+	bytecode, err := compiler.ReadBytecode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(bytecode), nil
+}
+
 func NewWithGlobalsStore(bytecode *compiler.Bytecode, s []object.Object) *VM {
 	vm := New(bytecode)
 	vm.globals = s
@@ -58,7 +73,15 @@ func (vm *VM) LastPoppedStackElem() object.Object {
 	return vm.stack[vm.sp]
 }
 
+// Run executes the bytecode. Errors are returned as *RuntimeError.
 func (vm *VM) Run() error {
+	if err := vm.run(); err != nil {
+		return vm.locate(err)
+	}
+	return nil
+}
+
+func (vm *VM) run() error {
 	var ip int
 	var ins code.Instructions
 	var op code.Opcode
@@ -83,7 +106,7 @@ func (vm *VM) Run() error {
 		case code.OpPop:
 			vm.pop()
 
-		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv:
+		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv, code.OpMod:
 			err := vm.executeBinaryOperation(op)
 			if err != nil {
 				return err
@@ -101,7 +124,7 @@ func (vm *VM) Run() error {
 				return err
 			}
 
-		case code.OpEqual, code.OpNotEqual, code.OpGreaterThan:
+		case code.OpEqual, code.OpNotEqual, code.OpGreaterThan, code.OpGreaterThanOrEqual:
 			err := vm.executeComparison(op)
 			if err != nil {
 				return err
@@ -189,6 +212,21 @@ func (vm *VM) Run() error {
 				return err
 			}
 
+		case code.OpSlice:
+			end := vm.pop()
+			start := vm.pop()
+			left := vm.pop()
+
+			result, err := object.Slice(left, start, end)
+			if err != nil {
+				return err
+			}
+
+			err = vm.push(result)
+			if err != nil {
+				return err
+			}
+
 		case code.OpCall:
 			numArgs := code.ReadUint8(ins[ip+1:])
 			vm.currentFrame().ip += 1
@@ -258,6 +296,36 @@ func (vm *VM) Run() error {
 				return err
 			}
 
+		case code.OpIter:
+			iterable := vm.pop()
+
+			iterator, ok := object.NewIterator(iterable)
+			if !ok {
+				return fmt.Errorf("cannot iterate over %s", iterable.Type())
+			}
+
+			err := vm.push(iterator)
+			if err != nil {
+				return err
+			}
+
+		case code.OpIterNext:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+
+			iterator := vm.pop().(*object.Iterator)
+
+			value, ok := iterator.Next()
+			if !ok {
+				vm.currentFrame().ip = pos - 1
+				break
+			}
+
+			err := vm.push(value)
+			if err != nil {
+				return err
+			}
+
 		case code.OpGetFree:
 			freeIndex := code.ReadUint8(ins[ip+1:])
 			vm.currentFrame().ip += 1
@@ -300,14 +368,68 @@ func (vm *VM) executeBinaryOperation(op code.Opcode) error {
 	switch {
 	case leftType == object.INTEGER_OBJ && rightType == object.INTEGER_OBJ:
 		return vm.executeBinaryIntegerOperation(op, left, right)
+	case isNumeric(left) && isNumeric(right):
+		return vm.executeBinaryFloatOperation(op, left, right)
 	case leftType == object.STRING_OBJ && rightType == object.STRING_OBJ:
 		return vm.executeBinaryStringOperation(op, left, right)
+	case leftType == object.AUDIO_OBJ && rightType == object.AUDIO_OBJ:
+		return vm.executeBinaryAudioOperation(op, left, right)
+	case leftType == object.AUDIO_OBJ && isNumeric(right) &&
+		(op == code.OpMul || op == code.OpDiv):
+		return vm.executeAudioGain(op, left.(*object.Audio), toFloat(right))
+	case isNumeric(left) && rightType == object.AUDIO_OBJ && op == code.OpMul:
+		return vm.executeAudioGain(op, right.(*object.Audio), toFloat(left))
 	default:
 		return fmt.Errorf("unsupported types for binary operation: %s %s",
 			leftType, rightType)
 	}
 }
 
+// executeBinaryAudioOperation mixes two buffers with + and -, and
+// ring-modulates them with *.
+func (vm *VM) executeBinaryAudioOperation(
+	op code.Opcode,
+	left, right object.Object,
+) error {
+	var f func(x, y float32) float32
+
+	switch op {
+	case code.OpAdd:
+		f = func(x, y float32) float32 { return x + y }
+	case code.OpSub:
+		f = func(x, y float32) float32 { return x - y }
+	case code.OpMul:
+		f = func(x, y float32) float32 { return x * y }
+	default:
+		return fmt.Errorf("unsupported types for binary operation: %s %s",
+			left.Type(), right.Type())
+	}
+
+	result, err := left.(*object.Audio).Combine(right.(*object.Audio), f)
+	if err != nil {
+		return err
+	}
+
+	return vm.push(result)
+}
+
+// executeAudioGain scales every sample of audio by gain, or divides it by
+// gain for OpDiv.
+func (vm *VM) executeAudioGain(op code.Opcode, audio *object.Audio, gain float64) error {
+	if op == code.OpDiv {
+		if gain == 0 {
+			return fmt.Errorf("division by zero")
+		}
+		return vm.push(audio.Map(func(x float32) float32 {
+			return float32(float64(x) / gain)
+		}))
+	}
+
+	return vm.push(audio.Map(func(x float32) float32 {
+		return float32(float64(x) * gain)
+	}))
+}
+
 func (vm *VM) executeBinaryIntegerOperation(
 	op code.Opcode,
 	left, right object.Object,
@@ -325,7 +447,15 @@ func (vm *VM) executeBinaryIntegerOperation(
 	case code.OpMul:
 		result = leftValue * rightValue
 	case code.OpDiv:
+		if rightValue == 0 {
+			return fmt.Errorf("division by zero")
+		}
 		result = leftValue / rightValue
+	case code.OpMod:
+		if rightValue == 0 {
+			return fmt.Errorf("modulo by zero")
+		}
+		result = leftValue % rightValue
 	default:
 		return fmt.Errorf("unknown integer operator: %d", op)
 	}
@@ -333,14 +463,51 @@ func (vm *VM) executeBinaryIntegerOperation(
 	return vm.push(&object.Integer{Value: result})
 }
 
+// executeBinaryFloatOperation handles arithmetic where at least one operand
+// is a FLOAT; an INTEGER operand is promoted to FLOAT first.
+func (vm *VM) executeBinaryFloatOperation(
+	op code.Opcode,
+	left, right object.Object,
+) error {
+	leftValue := toFloat(left)
+	rightValue := toFloat(right)
+
+	var result float64
+
+	switch op {
+	case code.OpAdd:
+		result = leftValue + rightValue
+	case code.OpSub:
+		result = leftValue - rightValue
+	case code.OpMul:
+		result = leftValue * rightValue
+	case code.OpDiv:
+		result = leftValue / rightValue
+	case code.OpMod:
+		result = math.Mod(leftValue, rightValue)
+	default:
+		return fmt.Errorf("unknown float operator: %d", op)
+	}
+
+	return vm.push(&object.Float{Value: result})
+}
+
 func (vm *VM) executeComparison(op code.Opcode) error {
 	right := vm.pop()
 	left := vm.pop()
 
-	if left.Type() == object.INTEGER_OBJ || right.Type() == object.INTEGER_OBJ {
+	if left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ {
 		return vm.executeIntegerComparison(op, left, right)
 	}
 
+	if isNumeric(left) && isNumeric(right) {
+		return vm.executeFloatComparison(op, left, right)
+	}
+
+	if left.Type() == object.AUDIO_OBJ && right.Type() == object.AUDIO_OBJ {
+		return vm.executeAudioComparison(op, left, right)
+	}
+
 	switch op {
 	case code.OpEqual:
 		return vm.push(nativeBoolToBooleanObject(right == left))
@@ -366,11 +533,51 @@ func (vm *VM) executeIntegerComparison(
 		return vm.push(nativeBoolToBooleanObject(rightValue != leftValue))
 	case code.OpGreaterThan:
 		return vm.push(nativeBoolToBooleanObject(leftValue > rightValue))
+	case code.OpGreaterThanOrEqual:
+		return vm.push(nativeBoolToBooleanObject(leftValue >= rightValue))
 	default:
 		return fmt.Errorf("unknown operator: %d", op)
 	}
 }
 
+func (vm *VM) executeFloatComparison(
+	op code.Opcode,
+	left, right object.Object,
+) error {
+	leftValue := toFloat(left)
+	rightValue := toFloat(right)
+
+	switch op {
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(rightValue == leftValue))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(rightValue != leftValue))
+	case code.OpGreaterThan:
+		return vm.push(nativeBoolToBooleanObject(leftValue > rightValue))
+	case code.OpGreaterThanOrEqual:
+		return vm.push(nativeBoolToBooleanObject(leftValue >= rightValue))
+	default:
+		return fmt.Errorf("unknown operator: %d", op)
+	}
+}
+
+func (vm *VM) executeAudioComparison(
+	op code.Opcode,
+	left, right object.Object,
+) error {
+	equal := left.(*object.Audio).Equal(right.(*object.Audio))
+
+	switch op {
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(equal))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(!equal))
+	default:
+		return fmt.Errorf("unknown operator: %d (%s %s)",
+			op, left.Type(), right.Type())
+	}
+}
+
 func (vm *VM) executeBangOperator() error {
 	operand := vm.pop()
 
@@ -389,12 +596,16 @@ func (vm *VM) executeBangOperator() error {
 func (vm *VM) executeMinusOperator() error {
 	operand := vm.pop()
 
-	if operand.Type() != object.INTEGER_OBJ {
+	switch operand := operand.(type) {
+	case *object.Integer:
+		return vm.push(&object.Integer{Value: -operand.Value})
+	case *object.Float:
+		return vm.push(&object.Float{Value: -operand.Value})
+	case *object.Audio:
+		return vm.push(operand.Map(func(x float32) float32 { return -x }))
+	default:
 		return fmt.Errorf("unsupported type for negation: %s", operand.Type())
 	}
-
-	value := operand.(*object.Integer).Value
-	return vm.push(&object.Integer{Value: -value})
 }
 
 func (vm *VM) executeBinaryStringOperation(
@@ -447,6 +658,10 @@ func (vm *VM) executeIndexExpression(left, index object.Object) error {
 		return vm.executeArrayIndex(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return vm.executeHashIndex(left, index)
+	case left.Type() == object.AUDIO_OBJ && index.Type() == object.INTEGER_OBJ:
+		return vm.executeAudioIndex(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return vm.executeStringIndex(left, index)
 	default:
 		return fmt.Errorf("index operator not supported: %s", left.Type())
 	}
@@ -464,6 +679,29 @@ func (vm *VM) executeArrayIndex(array, index object.Object) error {
 	return vm.push(arrayObject.Elements[i])
 }
 
+// executeAudioIndex pushes frame i of the buffer: a FLOAT for mono audio and
+// an ARRAY with one FLOAT per channel otherwise.
+func (vm *VM) executeAudioIndex(audio, index object.Object) error {
+	audioObject := audio.(*object.Audio)
+	i := index.(*object.Integer).Value
+	max := int64(audioObject.Frames() - 1)
+
+	if i < 0 || i > max {
+		return vm.push(Null)
+	}
+
+	return vm.push(audioObject.FrameObject(int(i)))
+}
+
+func (vm *VM) executeStringIndex(str, index object.Object) error {
+	char, ok := str.(*object.String).CharAt(int(index.(*object.Integer).Value))
+	if !ok {
+		return vm.push(Null)
+	}
+
+	return vm.push(char)
+}
+
 func (vm *VM) executeHashIndex(hash, index object.Object) error {
 	hashObject := hash.(*object.Hash)
 
@@ -512,6 +750,10 @@ func (vm *VM) callClosure(cl *object.Closure, numArgs int) error {
 			cl.Fn.NumParameters, numArgs)
 	}
 
+	if vm.framesIndex >= MaxFrames {
+		return fmt.Errorf("call stack overflow")
+	}
+
 	frame := NewFrame(cl, vm.sp-numArgs)
 	vm.pushFrame(frame)
 
@@ -572,3 +814,18 @@ func isTruthy(obj object.Object) bool {
 		return true
 	}
 }
+
+func isNumeric(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.Float:
+		return obj.Value
+	default:
+		return 0
+	}
+}
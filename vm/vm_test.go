@@ -1,13 +1,16 @@
 package vm
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 	"testing"
 	"wavy/ast"
 	"wavy/compiler"
 	"wavy/lexer"
 	"wavy/object"
 	"wavy/parser"
+	"wavy/token"
 )
 
 func TestIntegerArithmetic(t *testing.T) {
@@ -29,6 +32,117 @@ func TestIntegerArithmetic(t *testing.T) {
 		{"-10", -10},
 		{"-50 + 100 + -50", 0},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"7 % -3", 1},
+		{"6 % 3", 0},
+		{"1 + 10 % 4 * 2", 5},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestFloatArithmetic(t *testing.T) {
+	tests := []vmTestCase{
+		{"0.5", 0.5},
+		{"0.5 + 0.25", 0.75},
+		{"1.5 - 0.5", 1.0},
+		{"0.5 * 4", 2.0},
+		{"2 * 0.5", 1.0},
+		{"1 / 4.0", 0.25},
+		{"-1.25", -1.25},
+		{"440.0 * 2 + 1", 881.0},
+		{"1 / 2", 0},
+		{"1.0 / 0.0 > 1000000", true},
+		{"7.5 % 2", 1.5},
+		{"-7.5 % 2", -1.5},
+		{"7 % 2.5", 2.0},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestNumericComparisons(t *testing.T) {
+	tests := []vmTestCase{
+		{"0.5 < 1", true},
+		{"1 < 0.5", false},
+		{"1.5 > 1.25", true},
+		{"1.0 == 1", true},
+		{"1 == 1.0", true},
+		{"0.5 != 0.5", false},
+		{"0.5 == 0.25 + 0.25", true},
+		{"1 == true", false},
+		{"1 <= 1", true},
+		{"1 <= 0", false},
+		{"0.5 <= 1", true},
+		{"1 >= 1", true},
+		{"1 >= 2", false},
+		{"2 >= 1.5", true},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestDivisionByZero(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 / 0", "division by zero at line 1, position 3"},
+		{"1 % 0", "modulo by zero at line 1, position 3"},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		err := comp.Compile(parse(tt.input))
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		vm := New(comp.Bytecode())
+		err = vm.Run()
+		if err == nil {
+			t.Fatalf("expected VM error but resulted in none.")
+		}
+
+		if err.Error() != tt.expected {
+			t.Fatalf("wrong VM error: want=%q, got=%q", tt.expected, err)
+		}
+	}
+}
+
+func TestAudioArithmeticErrors(t *testing.T) {
+	tests := []vmErrorTestCase{
+		{"sine(1, 1, 8) + sine(1, 1, 4)", "mismatched sample rates: 8Hz and 4Hz at line 1, position 15"},
+		{"let a = silence(1, 8); a * (a + 1)", "unsupported types for binary operation: AUDIO INTEGER at line 1, position 31"},
+		{"silence(1, 8) / silence(1, 8)", "unsupported types for binary operation: AUDIO AUDIO at line 1, position 15"},
+		{"1 / silence(1, 8)", "unsupported types for binary operation: INTEGER AUDIO at line 1, position 3"},
+		{"silence(1, 8) / 0", "division by zero at line 1, position 15"},
+	}
+
+	runVmErrorTests(t, tests)
+}
+
+func TestLogicalOperators(t *testing.T) {
+	tests := []vmTestCase{
+		{"true && true", true},
+		{"true && false", false},
+		{"false && true", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"true || false", true},
+		{"1 && \"a\"", true},
+		{"0 || if (false) { 1 }", true},
+		{"if (false) { 1 } || false", false},
+		{"1 < 2 && 2 < 3", true},
+		{"1 > 2 || 2 > 3 || 3 > 2", true},
+		{"!(true && false)", true},
+		{"let a = []; len(a) > 0 && a[0] > 1", false},
+		{"let a = [2]; len(a) > 0 && a[0] > 1", true},
+		// the right operand is not evaluated when the left decides
+		{"false && 1 / 0", false},
+		{"true || 1 / 0", true},
+		{"let fail = fn() { 1 / 0 }; false && fail() || true || fail()", true},
 	}
 
 	runVmTests(t, tests)
@@ -84,6 +198,284 @@ func TestConditionals(t *testing.T) {
 	runVmTests(t, tests)
 }
 
+func TestLoops(t *testing.T) {
+	tests := []vmTestCase{
+		{
+			input: `
+			let i = 0;
+			let sum = 0;
+			while (i < 5) {
+				let i = i + 1;
+				let sum = sum + i;
+			}
+			sum;
+			`,
+			expected: 15,
+		},
+		{
+			input: `
+			let sum = 0;
+			for (x in [1, 2, 3, 4]) {
+				if (x == 2) { continue; }
+				if (x == 4) { break; }
+				let sum = sum + x;
+			}
+			sum;
+			`,
+			expected: 4,
+		},
+		{
+			input: `
+			let out = "";
+			for (c in "wavy") { let out = c + out; }
+			out;
+			`,
+			expected: "yvaw",
+		},
+		{
+			input: `
+			let keys = [];
+			for (k in {3: "c", 1: "a", 2: "b"}) { let keys = push(keys, k); }
+			keys;
+			`,
+			expected: []int{1, 2, 3},
+		},
+		{
+			input: `
+			let pairs = [];
+			for (a in [1, 2]) {
+				for (b in [10, 20]) {
+					if (b == 20) { break; }
+					let pairs = push(pairs, a + b);
+				}
+			}
+			pairs;
+			`,
+			expected: []int{11, 12},
+		},
+		{
+			input: `
+			let sumTo = fn(n) {
+				let total = 0;
+				let i = 0;
+				while (true) {
+					if (i > n) { return total; }
+					let total = total + i;
+					let i = i + 1;
+				}
+			};
+			sumTo(10);
+			`,
+			expected: 55,
+		},
+		{
+			input: `
+			let countdown = fn(n) {
+				while (n > 0) { let n = n - 1; }
+			};
+			countdown(3);
+			`,
+			expected: Null,
+		},
+		{
+			input:    `if (true) { while (false) {} }`,
+			expected: Null,
+		},
+		{
+			input: `
+			let big = [];
+			let i = 0;
+			while (i < 2000) {
+				let big = push(big, i);
+				let i = i + 1;
+			}
+			let sum = 0;
+			for (x in big) { let sum = sum + x; }
+			sum;
+			`,
+			expected: 1999000,
+		},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestLoopsOverAudio(t *testing.T) {
+	globals := map[string]object.Object{
+		"mono": &object.Audio{
+			Samples:    []float32{0.25, 0.5, 0.25},
+			SampleRate: 4, Channels: 1, BitDepth: 16,
+		},
+		"stereo": &object.Audio{
+			Samples:    []float32{0, 0.25, 0.5, 0.75},
+			SampleRate: 4, Channels: 2, BitDepth: 16,
+		},
+	}
+
+	tests := []vmTestCase{
+		{"let sum = 0; for (s in mono) { let sum = sum + s; } sum", 1.0},
+		{"let last = 0; for (f in stereo) { let last = f; } last", []float64{0.5, 0.75}},
+	}
+
+	runVmTestsWithGlobals(t, tests, globals)
+}
+
+func TestIteratingNonIterable(t *testing.T) {
+	program := parse("for (x in 1) { x }")
+
+	comp := compiler.New()
+	err := comp.Compile(program)
+	if err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	vm := New(comp.Bytecode())
+	err = vm.Run()
+	if err == nil {
+		t.Fatalf("expected VM error but resulted in none.")
+	}
+
+	expected := "cannot iterate over INTEGER at line 1, position 1"
+	if err.Error() != expected {
+		t.Fatalf("wrong VM error: want=%q, got=%q", expected, err)
+	}
+}
+
+func TestRuntimeErrorTraceback(t *testing.T) {
+	input := `let inner = fn(x) {
+  x + "a"
+};
+let outer = fn(x) { inner(x) };
+let run = fn() { fn() { outer(1) }() };
+run()`
+
+	comp := compiler.New()
+	if err := comp.Compile(parse(input)); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	err := New(comp.Bytecode()).Run()
+	runtimeErr, ok := err.(*RuntimeError)
+	if !ok {
+		t.Fatalf("error is not *RuntimeError. got=%T (%v)", err, err)
+	}
+
+	expected := []struct {
+		function string
+		line     int
+	}{
+		{"inner", 2},
+		{"outer", 4},
+		{"<anonymous>", 5},
+		{"run", 5},
+		{"<main>", 6},
+	}
+
+	if len(runtimeErr.Frames) != len(expected) {
+		t.Fatalf("wrong number of frames. want=%d, got=%d\n%s",
+			len(expected), len(runtimeErr.Frames), runtimeErr.Traceback())
+	}
+
+	for i, want := range expected {
+		frame := runtimeErr.Frames[i]
+		if frame.Function != want.function || frame.Pos.Line != want.line {
+			t.Errorf("frames[%d] wrong. want=%s on line %d, got=%s",
+				i, want.function, want.line, frame)
+		}
+	}
+
+	if runtimeErr.Frames[0].Offset != 5 {
+		t.Errorf("wrong offset in innermost frame. want=5, got=%d",
+			runtimeErr.Frames[0].Offset)
+	}
+}
+
+func TestRecursionTracebackIsCollapsed(t *testing.T) {
+	comp := compiler.New()
+	if err := comp.Compile(parse("let f = fn(n) { f(n + 1) }; f(0)")); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	err := New(comp.Bytecode()).Run()
+	runtimeErr, ok := err.(*RuntimeError)
+	if !ok {
+		t.Fatalf("error is not *RuntimeError. got=%T (%v)", err, err)
+	}
+
+	traceback := runtimeErr.Traceback()
+	if !strings.Contains(traceback, "more time(s)") {
+		t.Errorf("recursive frames not collapsed:\n%s", traceback)
+	}
+	if lines := strings.Count(traceback, "\n"); lines > 10 {
+		t.Errorf("traceback too long: %d lines", lines)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []vmTestCase{
+		{`let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } }; fib(10)`, 55},
+		{`let greet = fn(name) { "hello " + name }; greet("wavy")`, "hello wavy"},
+		{`let total = 0.5; for (x in [1, 2]) { let total = total + x; } total`, 3.5},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		if err := comp.Compile(parse(tt.input)); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		var buf bytes.Buffer
+		if _, err := comp.Bytecode().WriteTo(&buf); err != nil {
+			t.Fatalf("could not write bytecode: %s", err)
+		}
+
+		vm, err := Load(&buf)
+		if err != nil {
+			t.Fatalf("could not load bytecode: %s", err)
+		}
+
+		if err := vm.Run(); err != nil {
+			t.Fatalf("vm error: %s", err)
+		}
+
+		testExpectedObject(t, tt.expected, vm.LastPoppedStackElem())
+	}
+}
+
+func TestRuntimeErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected token.Position
+	}{
+		{"1 + true", token.Position{Line: 1, Column: 3}},
+		{"let a = 1;\nlet b = a;\nb - \"x\"", token.Position{Line: 3, Column: 3}},
+		{"let f = fn(x) {\n  x * true\n};\nf(2)", token.Position{Line: 2, Column: 5}},
+		{"let h = {};\nh[fn() {}]", token.Position{Line: 2, Column: 2}},
+	}
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		if err := comp.Compile(parse(tt.input)); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		err := New(comp.Bytecode()).Run()
+		if err == nil {
+			t.Fatalf("expected VM error for %q", tt.input)
+		}
+
+		runtimeErr, ok := err.(*RuntimeError)
+		if !ok {
+			t.Fatalf("error is not *RuntimeError. got=%T (%s)", err, err)
+		}
+
+		if runtimeErr.Pos != tt.expected {
+			t.Errorf("wrong position for %q. want=%s, got=%s",
+				tt.input, tt.expected, runtimeErr.Pos)
+		}
+	}
+}
+
 func TestGlobalLetStatements(t *testing.T) {
 	tests := []vmTestCase{
 		{"let one = 1; one", 1},
@@ -99,6 +491,9 @@ func TestStringExpressions(t *testing.T) {
 		{`"wavy"`, "wavy"},
 		{`"wa" + "vy"`, "wavy"},
 		{`"wa" + "vy" + "wave"`, "wavywave"},
+		{`"line\n" + "\ttab"`, "line\n\ttab"},
+		{`"\"quoted\""`, `"quoted"`},
+		{`"caf\u00e9"`, "café"},
 	}
 
 	runVmTests(t, tests)
@@ -150,11 +545,60 @@ func TestIndexExpressions(t *testing.T) {
 		{"{1: 1, 2: 2}[2]", 2},
 		{"{1: 1}[0]", Null},
 		{"{}[0]", Null},
+		{"{1: 1, 2: 2}[2.0]", 2},
+		{"{0.0: 7}[-0.0]", 7},
+		{"{1.5: 3}[1.5]", 3},
+		{"{1: 1}[1.5]", Null},
+		{`"wavy"[0]`, "w"},
+		{`"wavy"[3]`, "y"},
+		{`"wavy"[4]`, Null},
+		{`"wavy"[-1]`, Null},
+		{`"café.wav"[3]`, "é"},
+		{`"café.wav"[4]`, "."},
+		{`"🎵 intro"[0]`, "🎵"},
 	}
 
 	runVmTests(t, tests)
 }
 
+func TestSliceExpressions(t *testing.T) {
+	tests := []vmTestCase{
+		{"[1, 2, 3, 4][1:3]", []int{2, 3}},
+		{"[1, 2, 3, 4][2:]", []int{3, 4}},
+		{"[1, 2, 3, 4][:1]", []int{1}},
+		{"[1, 2, 3, 4][:]", []int{1, 2, 3, 4}},
+		{"[1, 2, 3, 4][-2:]", []int{3, 4}},
+		{"[1, 2, 3, 4][:-3]", []int{1}},
+		{"[1, 2, 3, 4][-99:99]", []int{1, 2, 3, 4}},
+		{"[1, 2, 3, 4][3:1]", []int{}},
+		{"let xs = [1, 2]; let ys = xs[:]; push(ys, 3); len(xs)", 2},
+		{`"waveform"[0:4]`, "wave"},
+		{`"waveform"[4:]`, "form"},
+		{`"café.wav"[:-4]`, "café"},
+		{`"🎵 intro"[2:]`, "intro"},
+		{`"abc"[5:]`, ""},
+		{"len(sine(1, 1, 8)[2:6])", 4},
+		{"sine(1, 1, 8)[2:6][0]", 1.0},
+		{"len(silence(2, 8)[0.5:1.5])", 8},
+		{"len(silence(2, 8)[-0.25:])", 2},
+		{"len(silence(2, 8)[:1])", 1},
+		{"sine(1, 1, 8)[0.25:] == sine(1, 1, 8)[2:]", true},
+	}
+
+	runVmTests(t, tests)
+}
+
+func TestSliceErrors(t *testing.T) {
+	tests := []vmErrorTestCase{
+		{"[1, 2][1.0:]", "slice bounds must be INTEGER, got FLOAT at line 1, position 7"},
+		{`"ab"[:"b"]`, "slice bounds must be INTEGER, got STRING at line 1, position 5"},
+		{`silence(1, 8)[true:]`, "slice bounds must be INTEGER or FLOAT, got BOOLEAN at line 1, position 14"},
+		{"{1: 2}[1:]", "slice operator not supported: HASH at line 1, position 7"},
+	}
+
+	runVmErrorTests(t, tests)
+}
+
 func TestCallingFunctionsWithoutArguments(t *testing.T) {
 	tests := []vmTestCase{
 		{
@@ -381,15 +825,15 @@ func TestCallingFunctionsWithWrongArguments(t *testing.T) {
 	tests := []vmTestCase{
 		{
 			input:    `fn() { 1; }(1);`,
-			expected: `wrong number of arguments: want=0, got=1`,
+			expected: `wrong number of arguments: want=0, got=1 at line 1, position 12`,
 		},
 		{
 			input:    `fn(a) { a; }();`,
-			expected: `wrong number of arguments: want=1, got=0`,
+			expected: `wrong number of arguments: want=1, got=0 at line 1, position 13`,
 		},
 		{
 			input:    `fn(a, b) { a + b; }(1);`,
-			expected: `wrong number of arguments: want=2, got=1`,
+			expected: `wrong number of arguments: want=2, got=1 at line 1, position 20`,
 		},
 	}
 
@@ -419,6 +863,8 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len("")`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
+		{`len("café")`, 4},
+		{`len("🎵")`, 1},
 		{
 			`len(1)`,
 			&object.Error{
@@ -455,6 +901,102 @@ func TestBuiltinFunctions(t *testing.T) {
 				Message: "argument to `push` must be ARRAY, got INTEGER",
 			},
 		},
+		{`len(sine(440, 0.5, 8000))`, 4000},
+		{`sine(1, 1, 4)[1]`, 1.0},
+		{`square(1, 1, 4, 0.5)[2]`, -0.5},
+		{`silence(1, 8000) == silence(1.0, 8000)`, true},
+		{`white_noise(1, 8000) == white_noise(1, 8000, 1, 0)`, true},
+		{`silence(1, 0)`,
+			&object.Error{
+				Message: "sample rate must be positive, got 0",
+			},
+		},
+		{`normalize(sine(1, 1, 4, 0.25))[1]`, 1.0},
+		{`gain(sine(1, 1, 4), -6.0206)[1]`, 0.5},
+		{`reverse(sine(1, 1, 4))[2]`, 1.0},
+		{`len(resample(silence(1, 8000), 44100))`, 44100},
+		{`pan(sine(1, 1, 4), -1)[1]`, []float64{1.0, 0.0}},
+		{`to_mono(pan(silence(1, 8), 0)) == silence(1, 8)`, true},
+		{`fade_in(silence(1, 8), 2.0) == silence(1, 8)`, true},
+		{`len(lowpass(sine(440, 1, 8000), 1000))`, 8000},
+		{`notch(silence(1, 8000), 50, 10) == silence(1, 8000)`, true},
+		{`high_shelf(sine(1, 1, 4), 1, 0)[1]`, 1.0},
+		{`lowpass(silence(1, 8000), 5000)`,
+			&object.Error{
+				Message: "filter frequency must be between 0 and 4000 Hz, got 5000",
+			},
+		},
+		{`fft([1, 1, 1, 1])[0]`, []float64{4, 0, 0, 0}},
+		{`let x = fft([1, 2, 3]); ifft(x[0], x[1])[2]`, 3.0},
+		{`len(spectrogram(silence(1, 8000), 512, 256))`, 30},
+		{`peak_frequency(sine(441, 1, 44100))`, 441.0},
+		{`peak_frequency([1, 2], 8000)`, 0.0},
+		{`peak(square(1, 1, 8, 0.5))`, -6.020599913279624},
+		{`crest_factor(square(1, 1, 8))`, 0.0},
+		{`let lufs = integrated_lufs(sine(1000, 1, 48000, 0.1)); lufs > -23.1 && lufs < -22.9`, true},
+		{`true_peak(silence(1, 8000)) < -1000`, true},
+		{`delay(square(1, 1, 4), 0.5, 0, 1)[2]`, 1.0},
+		{`echo(square(1, 1, 4), [[0.25, 0.5]])[3]`, -1.5},
+		{`len(reverb(chorus(flanger(sine(440, 1, 8000)))))`, 8000},
+		{`checksum(sine(440, 1, 8000, 0.00001))`, "56d4af65701c26df20bd4021eda95b6e830348ce3a746086079fe89285548dc9"},
+		{`let out = compress(square(10, 1, 1000, 0.5), -20, 4, 0, 0, true); let r = out[1][999]; r > 10.48 && r < 10.49`, true},
+		{`peak(limit(sine(100, 1, 8000) * 2, -3)) <= -3`, true},
+		{`len(gate(white_noise(0.5, 8000, 0.001), -40, true)[1])`, 4000},
+		{`peak(expand(silence(0.1, 8000), -40, 2)) < -1000`, true},
+		{`len(concat(sine(1, 1, 8), silence(1, 8), sine(1, 1, 8), 0.25))`, 20},
+		{`let clips = split_on_silence(concat(sine(1, 1, 8), silence(1, 8), sine(1, 1, 8)), -60, 0.5); [len(clips), len(clips[1])]`, []int{2, 7}},
+		{`len(split_on_silence(silence(1, 8), -60, 1000000000000000000000.0))`, 1},
+		{`len(trim_silence(concat(silence(1, 8), square(1, 1, 8), silence(2, 8))))`, 8},
+		{`let gaps = detect_silence(concat(sine(1, 1, 100), silence(1, 100), sine(1, 1, 100))); [len(gaps) * 1.0, gaps[0]["start"], gaps[0]["end"]]`, []float64{1, 1, 2.01}},
+		{`let a = concat(sine(1, 1, 100), silence(1, 100)); let gap = detect_silence(a)[0]; peak(a[gap["start"]:gap["end"]]) < -1000`, true},
+		{`let lr = split_channels(merge_channels([square(1, 1, 4), square(1, 1, 4) * 0.5])); [len(lr) * 1.0, lr[0][0], lr[1][0]]`, []float64{2, 1, 0.5}},
+		{`let a = pan(sine(2, 1, 8), 0.3); merge_channels(split_channels(a)) == a`, true},
+		{`remix(merge_channels([square(1, 1, 4), silence(1, 4)]), [[1, 1], [0.5, -0.5], [0, 0]])[0]`, []float64{1, 0.5, 0}},
+		{`remix(silence(1, 8), [[1, 2]])`,
+			&object.Error{
+				Message: "row 1 of the remix matrix has 2 gains for 1-channel audio",
+			},
+		},
+		{`load("../audio/testdata/stereo16.aiff")[15]`, []float64{0.9375, -0.9375}},
+		{`let a = load("../audio/testdata/mono24.aifc"); [len(a) * 1.0, a[8]]`, []float64{16, 0.5}},
+		{`load_raw("../audio/testdata/stereo16be.raw", 8000, 2, "pcm16be")[4]`, []float64{0.25, -0.25}},
+		{`[load_raw("../audio/testdata/mono8u.raw", 8000, 1, "pcm8u")[10], load_raw("../audio/testdata/mono32fle.raw", 8000, 1, "float32le")[10]]`, []float64{0.625, 0.625}},
+		{`load_raw("x.raw", 8000, 1, "pcm16")`,
+			&object.Error{
+				Message: `could not load "x.raw": raw format "pcm16" must end in "le" or "be"`,
+			},
+		},
+		{`silence(0.0 / 0.0, 8000)`,
+			&object.Error{
+				Message: "duration must be finite, got NaN",
+			},
+		},
+		{`white_noise(100000000.0 * 100000000000.0, 8000)`,
+			&object.Error{
+				Message: "duration of 1e+19s at 8000Hz is too long",
+			},
+		},
+		{`resample(sine(1, 1, 8), 1000000000000000)`,
+			&object.Error{
+				Message: "resampling to 1000000000000000Hz gives too many samples",
+			},
+		},
+		{`len(spectrogram([1, 2, 3], 1000000000000000, 1))`, 0},
+		{`len(limit(sine(440, 1, 8000), -1, 1000000000000000000000.0))`, 8000},
+		{`len(concat([sine(440, 1, 8000), sine(440, 0.1, 8000), sine(440, 1, 8000)], 0.5))`, 15200},
+		{`len(spectrogram([1, 2, 3, 4], 2, 9223372036854775807))`, 1},
+		{`len(delay(pan(silence(0.01, 8000), 0), 1000000000000000.0))`, 80},
+		{`len(echo(pan(silence(0.01, 8000), 0), [[1000000000000000.0, 0.5]]))`, 80},
+		{`echo(silence(1, 8), [[1, 0.5, 2]])`,
+			&object.Error{
+				Message: "echo taps must be [seconds, gain] pairs, got [1, 0.5, 2]",
+			},
+		},
+		{`pan(silence(1, 8), 3)`,
+			&object.Error{
+				Message: "pan position must be between -1 and 1, got 3",
+			},
+		},
 	}
 
 	runVmTests(t, tests)
@@ -563,6 +1105,51 @@ func TestRecursiveFibonacci(t *testing.T) {
 	runVmTests(t, tests)
 }
 
+func TestAudioObjects(t *testing.T) {
+	globals := map[string]object.Object{
+		"mono": &object.Audio{
+			Samples:    []float32{0, 0.5, -0.5, 1},
+			SampleRate: 4, Channels: 1, BitDepth: 16,
+		},
+		"monoCopy": &object.Audio{
+			Samples:    []float32{0, 0.5, -0.5, 1},
+			SampleRate: 4, Channels: 1, BitDepth: 16,
+		},
+		"stereo": &object.Audio{
+			Samples:    []float32{0, 0.25, 0.5, 0.75},
+			SampleRate: 4, Channels: 2, BitDepth: 16,
+		},
+	}
+
+	tests := []vmTestCase{
+		{"len(mono)", 4},
+		{"len(stereo)", 2},
+		{"mono[1]", 0.5},
+		{"mono[3]", 1.0},
+		{"mono[4]", Null},
+		{"mono[-1]", Null},
+		{"stereo[1]", []float64{0.5, 0.75}},
+		{"mono == mono", true},
+		{"mono == monoCopy", true},
+		{"mono != monoCopy", false},
+		{"mono == stereo", false},
+		{"mono != stereo", true},
+		{"(mono + mono)[1]", 1.0},
+		{"(mono - monoCopy) == silence(1, 4)", true},
+		{"(mono * 0.5)[3]", 0.5},
+		{"(2 * mono)[2]", -1.0},
+		{"(mono / 2)[1]", 0.25},
+		{"(mono * mono)[2]", 0.25},
+		{"(stereo * stereo)[1]", []float64{0.25, 0.5625}},
+		{"-mono == mono * -1", true},
+		{"(-stereo)[1]", []float64{-0.5, -0.75}},
+		{"len(mono + silence(2, 4))", 8},
+		{"(mono + silence(2, 4))[7]", 0.0},
+	}
+
+	runVmTestsWithGlobals(t, tests, globals)
+}
+
 type vmTestCase struct {
 	input    string
 	expected interface{}
@@ -592,6 +1179,75 @@ func runVmTests(t *testing.T, tests []vmTestCase) {
 	}
 }
 
+// vmErrorTestCase is a program that stops with a runtime error.
+type vmErrorTestCase struct {
+	input    string
+	expected string
+}
+
+func runVmErrorTests(t *testing.T, tests []vmErrorTestCase) {
+	t.Helper()
+
+	for _, tt := range tests {
+		comp := compiler.New()
+		err := comp.Compile(parse(tt.input))
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		vm := New(comp.Bytecode())
+		err = vm.Run()
+		if err == nil {
+			t.Errorf("%q: expected VM error but resulted in none.", tt.input)
+			continue
+		}
+
+		if err.Error() != tt.expected {
+			t.Errorf("wrong VM error: want=%q, got=%q", tt.expected, err)
+		}
+	}
+}
+
+// runVmTestsWithGlobals runs tests with the given objects bound to global
+// names, for values that have no literal syntax.
+func runVmTestsWithGlobals(
+	t *testing.T,
+	tests []vmTestCase,
+	bindings map[string]object.Object,
+) {
+	t.Helper()
+
+	for _, tt := range tests {
+		program := parse(tt.input)
+
+		symbolTable := compiler.NewSymbolTable()
+		for i, v := range object.Builtins {
+			symbolTable.DefineBuiltin(i, v.Name)
+		}
+
+		globals := make([]object.Object, GlobalsSize)
+		for name, value := range bindings {
+			globals[symbolTable.Define(name).Index] = value
+		}
+
+		comp := compiler.NewWithState(symbolTable, []object.Object{})
+		err := comp.Compile(program)
+		if err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		vm := NewWithGlobalsStore(comp.Bytecode(), globals)
+		err = vm.Run()
+		if err != nil {
+			t.Fatalf("vm error: %s", err)
+		}
+
+		stackElem := vm.LastPoppedStackElem()
+
+		testExpectedObject(t, tt.expected, stackElem)
+	}
+}
+
 func parse(input string) *ast.Program {
 	l := lexer.New(input)
 	p := parser.New(l)
@@ -612,6 +1268,32 @@ func testExpectedObject(
 			t.Errorf("testIntegerObject failed: %s", err)
 		}
 
+	case float64:
+		err := testFloatObject(expected, actual)
+		if err != nil {
+			t.Errorf("testFloatObject failed: %s", err)
+		}
+
+	case []float64:
+		array, ok := actual.(*object.Array)
+		if !ok {
+			t.Errorf("object not Array: %T (%+v)", actual, actual)
+			return
+		}
+
+		if len(array.Elements) != len(expected) {
+			t.Errorf("wrong num of elements. want=%d, got=%d",
+				len(expected), len(array.Elements))
+			return
+		}
+
+		for i, expectedElem := range expected {
+			err := testFloatObject(expectedElem, array.Elements[i])
+			if err != nil {
+				t.Errorf("testFloatObject failed: %s", err)
+			}
+		}
+
 	case bool:
 		err := testBooleanObject(bool(expected), actual)
 		if err != nil {
@@ -702,6 +1384,21 @@ func testIntegerObject(expected int64, actual object.Object) error {
 	return nil
 }
 
+func testFloatObject(expected float64, actual object.Object) error {
+	result, ok := actual.(*object.Float)
+	if !ok {
+		return fmt.Errorf("object is not Float. got=%T (%+v)",
+			actual, actual)
+	}
+
+	if result.Value != expected {
+		return fmt.Errorf("object has wrong value. got=%g, want=%g",
+			result.Value, expected)
+	}
+
+	return nil
+}
+
 func testBooleanObject(expected bool, actual object.Object) error {
 	result, ok := actual.(*object.Boolean)
 	if !ok {